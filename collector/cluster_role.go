@@ -0,0 +1,74 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// clusterRoleLabel is added to the series of MongoDB with the role of the node in a sharded cluster.
+	clusterRoleLabel = "cluster_role"
+	// clusterRoleUnknown is the role if it could never be detected.
+	clusterRoleUnknown = "unknown"
+)
+
+// metricList is a collector of already collected metrics.
+type metricList []prometheus.Metric
+
+// Describe implements prometheus.Collector.
+func (list metricList) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range list {
+		ch <- m.Desc()
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (list metricList) Collect(ch chan<- prometheus.Metric) {
+	for _, m := range list {
+		ch <- m
+	}
+}
+
+// collectorCapture is a prometheus.Registerer keeping the collector passed to it,
+// used to get the collector wrapped by prometheus.WrapRegistererWith.
+type collectorCapture struct {
+	collector prometheus.Collector
+}
+
+// Register implements prometheus.Registerer.
+func (capture *collectorCapture) Register(c prometheus.Collector) error {
+	capture.collector = c
+	return nil
+}
+
+// MustRegister implements prometheus.Registerer.
+func (capture *collectorCapture) MustRegister(cs ...prometheus.Collector) {
+	for _, c := range cs {
+		_ = capture.Register(c)
+	}
+}
+
+// Unregister implements prometheus.Registerer.
+func (capture *collectorCapture) Unregister(prometheus.Collector) bool {
+	return false
+}
+
+// withClusterRole returns a collector of the metrics with the cluster_role label added.
+func withClusterRole(metrics []prometheus.Metric, role string) prometheus.Collector {
+	capture := &collectorCapture{}
+	prometheus.WrapRegistererWith(prometheus.Labels{clusterRoleLabel: role}, capture).MustRegister(metricList(metrics))
+	return capture.collector
+}
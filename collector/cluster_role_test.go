@@ -0,0 +1,39 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/percona/mongodb_exporter/testutils"
+)
+
+func TestWithClusterRole(t *testing.T) {
+	desc := prometheus.NewDesc("mongodb_test_metric", "A test metric", []string{"db"}, prometheus.Labels{"const": "value"})
+	metrics := []prometheus.Metric{
+		prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 1, "a"),
+		prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 2, "b"),
+	}
+
+	values := testutils.CollectMetrics(withClusterRole(metrics, "shardsvr").Collect, "db", "const", "cluster_role")
+
+	assert.Equal(t, map[string]float64{
+		"mongodb_test_metric/a/value/shardsvr": 1,
+		"mongodb_test_metric/b/value/shardsvr": 2,
+	}, values)
+}
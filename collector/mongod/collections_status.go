@@ -2,12 +2,13 @@ package mongod
 
 import (
 	"context"
+	"strconv"
+	"strings"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/log"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 var (
@@ -55,6 +56,63 @@ var (
 	}, []string{"db", "coll", "index"})
 )
 
+var (
+	collectionWTCacheBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "db_coll", "wiredtiger_cache_bytes"),
+		"The size in bytes of the collection data currently in the WiredTiger Cache",
+		[]string{"db", "coll"},
+		nil,
+	)
+	collectionWTCachePagesTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "db_coll", "wiredtiger_cache_pages_total"),
+		"The total number of collection pages read into/written from the WiredTiger Cache",
+		[]string{"db", "coll", "type"},
+		nil,
+	)
+	collectionWTReusableBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "db_coll", "wiredtiger_blockmanager_reusable_bytes"),
+		"The size in bytes of the collection file available for reuse, i.e. what compact could reclaim",
+		[]string{"db", "coll"},
+		nil,
+	)
+	collectionCompressionInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "db_coll", "compression_info"),
+		"The block compressor the collection was created with",
+		[]string{"db", "coll", "compressor"},
+		nil,
+	)
+	collectionInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "db_coll", "info"),
+		"The type and options of the collection",
+		[]string{"db", "coll", "type", "capped", "validator", "validation_level", "validation_action", "collation"},
+		nil,
+	)
+	collectionCappedMaxDocumentsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "db_coll", "capped_max_documents"),
+		"The maximum number of documents of the capped collection, 0 if unlimited",
+		[]string{"db", "coll"},
+		nil,
+	)
+	collectionCappedMaxSizeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "db_coll", "capped_max_size_bytes"),
+		"The maximum size in bytes of the capped collection",
+		[]string{"db", "coll"},
+		nil,
+	)
+	collectionCappedFillRatioDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "db_coll", "capped_fill_ratio"),
+		"The ratio between the size and the maximum size of the capped collection",
+		[]string{"db", "coll"},
+		nil,
+	)
+	collectionCompressionRatioDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "db_coll", "compression_ratio"),
+		"The ratio between the uncompressed data size and the storage size of the collection",
+		[]string{"db", "coll"},
+		nil,
+	)
+)
+
 // CollectionStatList contains stats from all collections
 type CollectionStatList struct {
 	Members []CollectionStatus
@@ -70,6 +128,77 @@ type CollectionStatus struct {
 	StorageSize int                `bson:"storageSize,omitempty"`
 	IndexesSize int                `bson:"totalIndexSize,omitempty"`
 	IndexSizes  map[string]float64 `bson:"indexSizes,omitempty"`
+	WiredTiger  *CollectionWTStats `bson:"wiredTiger,omitempty"`
+	Capped      bool               `bson:"capped,omitempty"`
+	Max         float64            `bson:"max,omitempty"`
+	MaxSize     float64            `bson:"maxSize,omitempty"`
+	// Type and Options come from listCollections
+	Type    string            `bson:"-"`
+	Options CollectionOptions `bson:"-"`
+}
+
+// CollectionOptions represents the listCollections options of a collection
+type CollectionOptions struct {
+	Validator        bson.Raw `bson:"validator,omitempty"`
+	ValidationLevel  string   `bson:"validationLevel,omitempty"`
+	ValidationAction string   `bson:"validationAction,omitempty"`
+	Collation        struct {
+		Locale string `bson:"locale,omitempty"`
+	} `bson:"collation,omitempty"`
+}
+
+// exportInfo exports the collection_info series.
+func (member *CollectionStatus) exportInfo(ch chan<- prometheus.Metric) {
+	collType := member.Type
+	if collType == "" {
+		// listCollections of MongoDB < 3.4 has no type
+		collType = "collection"
+	}
+	validationLevel, validationAction := "", ""
+	if member.Options.Validator != nil {
+		validationLevel, validationAction = "strict", "error"
+		if member.Options.ValidationLevel != "" {
+			validationLevel = member.Options.ValidationLevel
+		}
+		if member.Options.ValidationAction != "" {
+			validationAction = member.Options.ValidationAction
+		}
+	}
+	collation := member.Options.Collation.Locale
+	if collation == "" {
+		collation = "simple"
+	}
+	ch <- prometheus.MustNewConstMetric(collectionInfoDesc, prometheus.GaugeValue, 1,
+		member.Database, member.Name, collType,
+		strconv.FormatBool(member.Capped),
+		strconv.FormatBool(member.Options.Validator != nil),
+		validationLevel, validationAction, collation,
+	)
+}
+
+// CollectionWTStats represents the WiredTiger stats of a collection
+type CollectionWTStats struct {
+	CreationString string `bson:"creationString"`
+	BlockManager   struct {
+		FileBytesAvailableForReuse float64 `bson:"file bytes available for reuse"`
+	} `bson:"block-manager"`
+	Cache struct {
+		BytesInCache     float64 `bson:"bytes currently in the cache"`
+		PagesReadInto    float64 `bson:"pages read into cache"`
+		PagesWrittenFrom float64 `bson:"pages written from cache"`
+	} `bson:"cache"`
+}
+
+// Compressor returns the block_compressor of the creationString, "none" if it is not set.
+func (stats *CollectionWTStats) Compressor() string {
+	for _, option := range strings.Split(stats.CreationString, ",") {
+		if strings.HasPrefix(option, "block_compressor=") {
+			if compressor := strings.TrimPrefix(option, "block_compressor="); compressor != "" {
+				return compressor
+			}
+		}
+	}
+	return "none"
 }
 
 // Export exports database stats to prometheus
@@ -83,6 +212,11 @@ func (collStatList *CollectionStatList) Export(ch chan<- prometheus.Metric) {
 	collectionIndexesSize.Reset()
 	collectionIndexSize.Reset()
 	for _, member := range collStatList.Members {
+		member.exportInfo(ch)
+		if member.Type == "view" {
+			// views have no storage
+			continue
+		}
 		ls := prometheus.Labels{
 			"db":   member.Database,
 			"coll": member.Name,
@@ -101,6 +235,23 @@ func (collStatList *CollectionStatList) Export(ch chan<- prometheus.Metric) {
 			}
 			collectionIndexSize.With(ls).Set(size)
 		}
+		if member.Capped {
+			ch <- prometheus.MustNewConstMetric(collectionCappedMaxDocumentsDesc, prometheus.GaugeValue, member.Max, member.Database, member.Name)
+			ch <- prometheus.MustNewConstMetric(collectionCappedMaxSizeDesc, prometheus.GaugeValue, member.MaxSize, member.Database, member.Name)
+			if member.MaxSize > 0 {
+				ch <- prometheus.MustNewConstMetric(collectionCappedFillRatioDesc, prometheus.GaugeValue, float64(member.Size)/member.MaxSize, member.Database, member.Name)
+			}
+		}
+		if member.StorageSize > 0 {
+			ch <- prometheus.MustNewConstMetric(collectionCompressionRatioDesc, prometheus.GaugeValue, float64(member.Size)/float64(member.StorageSize), member.Database, member.Name)
+		}
+		if wt := member.WiredTiger; wt != nil {
+			ch <- prometheus.MustNewConstMetric(collectionWTCacheBytesDesc, prometheus.GaugeValue, wt.Cache.BytesInCache, member.Database, member.Name)
+			ch <- prometheus.MustNewConstMetric(collectionWTCachePagesTotalDesc, prometheus.CounterValue, wt.Cache.PagesReadInto, member.Database, member.Name, "read")
+			ch <- prometheus.MustNewConstMetric(collectionWTCachePagesTotalDesc, prometheus.CounterValue, wt.Cache.PagesWrittenFrom, member.Database, member.Name, "written")
+			ch <- prometheus.MustNewConstMetric(collectionWTReusableBytesDesc, prometheus.GaugeValue, wt.BlockManager.FileBytesAvailableForReuse, member.Database, member.Name)
+			ch <- prometheus.MustNewConstMetric(collectionCompressionInfoDesc, prometheus.GaugeValue, 1, member.Database, member.Name, wt.Compressor())
+		}
 	}
 	collectionSize.Collect(ch)
 	collectionObjectCount.Collect(ch)
@@ -119,12 +270,51 @@ func (collStatList *CollectionStatList) Describe(ch chan<- *prometheus.Desc) {
 	collectionStorageSize.Describe(ch)
 	collectionIndexes.Describe(ch)
 	collectionIndexesSize.Describe(ch)
+	collectionIndexSize.Describe(ch)
+	ch <- collectionWTCacheBytesDesc
+	ch <- collectionWTCachePagesTotalDesc
+	ch <- collectionWTReusableBytesDesc
+	ch <- collectionCompressionInfoDesc
+	ch <- collectionCompressionRatioDesc
+	ch <- collectionInfoDesc
+	ch <- collectionCappedMaxDocumentsDesc
+	ch <- collectionCappedMaxSizeDesc
+	ch <- collectionCappedFillRatioDesc
 }
 
 var (
 	logSuppressCS = make(map[string]bool)
 )
 
+// collectionListItem is a collection returned by listCollections.
+type collectionListItem struct {
+	Name    string            `bson:"name,omitempty"`
+	Type    string            `bson:"type,omitempty"`
+	Options CollectionOptions `bson:"options,omitempty"`
+}
+
+// timeseriesBucketsPrefix is the prefix of the collection storing the buckets of a time-series collection (5.0+).
+const timeseriesBucketsPrefix = "system.buckets."
+
+// withoutTimeseriesBuckets removes the system.buckets collections of the time-series collections in the list,
+// as collStats of a time-series collection already reports the storage of its buckets.
+func withoutTimeseriesBuckets(items []collectionListItem) []collectionListItem {
+	timeseries := make(map[string]bool)
+	for _, item := range items {
+		if item.Type == "timeseries" {
+			timeseries[item.Name] = true
+		}
+	}
+	var result []collectionListItem
+	for _, item := range items {
+		if strings.HasPrefix(item.Name, timeseriesBucketsPrefix) && timeseries[strings.TrimPrefix(item.Name, timeseriesBucketsPrefix)] {
+			continue
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
 // GetCollectionStatList returns stats for a given database
 func GetCollectionStatList(client *mongo.Client) *CollectionStatList {
 	collectionStatList := &CollectionStatList{}
@@ -139,7 +329,7 @@ func GetCollectionStatList(client *mongo.Client) *CollectionStatList {
 	}
 	delete(logSuppressCS, "")
 	for _, db := range dbNames {
-		c, err := client.Database(db).ListCollections(context.TODO(), bson.M{}, options.ListCollections().SetNameOnly(true))
+		c, err := client.Database(db).ListCollections(context.TODO(), bson.M{})
 		if err != nil {
 			_, logSFound := logSuppressCS[db]
 			if !logSFound {
@@ -147,20 +337,27 @@ func GetCollectionStatList(client *mongo.Client) *CollectionStatList {
 				logSuppressCS[db] = true
 			}
 		} else {
-
-			type collListItem struct {
-				Name string `bson:"name,omitempty"`
-				Type string `bson:"type,omitempty"`
-			}
-
 			delete(logSuppressCS, db)
+			var items []collectionListItem
 			for c.Next(context.TODO()) {
-				coll := &collListItem{}
-				err := c.Decode(&coll)
-				if err != nil {
+				coll := collectionListItem{}
+				if err := c.Decode(&coll); err != nil {
 					log.Error(err)
 					continue
 				}
+				items = append(items, coll)
+			}
+			for _, coll := range withoutTimeseriesBuckets(items) {
+				// collStats fails on views
+				if coll.Type == "view" {
+					collectionStatList.Members = append(collectionStatList.Members, CollectionStatus{
+						Database: db,
+						Name:     coll.Name,
+						Type:     coll.Type,
+						Options:  coll.Options,
+					})
+					continue
+				}
 				collStatus := CollectionStatus{}
 				err = client.Database(db).RunCommand(context.TODO(), bson.D{{"collStats", coll.Name}, {"scale", 1}}).Decode(&collStatus)
 				if err != nil {
@@ -173,6 +370,8 @@ func GetCollectionStatList(client *mongo.Client) *CollectionStatList {
 					delete(logSuppressCS, db+"."+coll.Name)
 					collStatus.Database = db
 					collStatus.Name = coll.Name
+					collStatus.Type = coll.Type
+					collStatus.Options = coll.Options
 					collectionStatList.Members = append(collectionStatList.Members, collStatus)
 				}
 			}
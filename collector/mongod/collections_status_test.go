@@ -0,0 +1,117 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/percona/mongodb_exporter/testutils"
+)
+
+func TestCollectionStatusWiredTiger(t *testing.T) {
+	collStatus := CollectionStatus{}
+	testutils.MustDecodeBSON(t, bson.M{
+		"size":           int32(3000),
+		"count":          int32(10),
+		"storageSize":    int32(1000),
+		"totalIndexSize": int32(200),
+		"indexSizes":     bson.M{"_id_": int32(200)},
+		"wiredTiger": bson.M{
+			"creationString": "access_pattern_hint=none,allocation_size=4KB,app_metadata=(formatVersion=1),block_allocation=best,block_compressor=zstd,cache_resident=false",
+			"block-manager":  bson.M{"file bytes available for reuse": int32(4096)},
+			"cache": bson.M{
+				"bytes currently in the cache": int64(2048),
+				"pages read into cache":        int64(5),
+				"pages written from cache":     int64(7),
+			},
+		},
+	}, &collStatus)
+	collStatus.Database = "test"
+	collStatus.Name = "coll"
+
+	list := &CollectionStatList{Members: []CollectionStatus{collStatus}}
+	values := testutils.CollectMetrics(list.Export, "type", "compressor")
+
+	assert.Equal(t, 2048.0, values["mongodb_mongod_db_coll_wiredtiger_cache_bytes"])
+	assert.Equal(t, 5.0, values["mongodb_mongod_db_coll_wiredtiger_cache_pages_total/read"])
+	assert.Equal(t, 7.0, values["mongodb_mongod_db_coll_wiredtiger_cache_pages_total/written"])
+	assert.Equal(t, 4096.0, values["mongodb_mongod_db_coll_wiredtiger_blockmanager_reusable_bytes"])
+	assert.Equal(t, 1.0, values["mongodb_mongod_db_coll_compression_info/zstd"])
+	assert.Equal(t, 3.0, values["mongodb_mongod_db_coll_compression_ratio"])
+}
+
+func TestCollectionWTStatsCompressor(t *testing.T) {
+	assert.Equal(t, "snappy", (&CollectionWTStats{CreationString: "allocation_size=4KB,block_compressor=snappy"}).Compressor())
+	assert.Equal(t, "none", (&CollectionWTStats{CreationString: "allocation_size=4KB,block_compressor=,cache_resident=false"}).Compressor())
+	assert.Equal(t, "none", (&CollectionWTStats{}).Compressor())
+}
+
+func TestCollectionStatusInfo(t *testing.T) {
+	item := collectionListItem{}
+	testutils.MustDecodeBSON(t, bson.M{
+		"name": "events",
+		"type": "collection",
+		"options": bson.M{
+			"capped":           true,
+			"size":             int32(4096),
+			"validator":        bson.M{"a": bson.M{"$exists": true}},
+			"validationAction": "warn",
+			"collation":        bson.M{"locale": "fr", "strength": int32(1)},
+		},
+	}, &item)
+
+	list := &CollectionStatList{Members: []CollectionStatus{
+		{Database: "test", Name: "events", Type: "collection", Options: item.Options, Capped: true, Max: 100, MaxSize: 4096, Size: 1024, StorageSize: 512},
+		{Database: "test", Name: "events_view", Type: "view"},
+		{Database: "test", Name: "plain"},
+	}}
+	values := testutils.CollectMetrics(list.Export, "coll", "type", "capped", "validator", "validation_level", "validation_action", "collation")
+
+	assert.Equal(t, 1.0, values["mongodb_mongod_db_coll_info/events/collection/true/true/strict/warn/fr"])
+	assert.Equal(t, 1.0, values["mongodb_mongod_db_coll_info/events_view/view/false/false///simple"])
+	assert.Equal(t, 1.0, values["mongodb_mongod_db_coll_info/plain/collection/false/false///simple"])
+
+	assert.Equal(t, 100.0, values["mongodb_mongod_db_coll_capped_max_documents/events"])
+	assert.Equal(t, 4096.0, values["mongodb_mongod_db_coll_capped_max_size_bytes/events"])
+	assert.Equal(t, 0.25, values["mongodb_mongod_db_coll_capped_fill_ratio/events"])
+	assert.NotContains(t, values, "mongodb_mongod_db_coll_size/events_view")
+	assert.NotContains(t, values, "mongodb_mongod_db_coll_capped_fill_ratio/plain")
+	assert.Contains(t, values, "mongodb_mongod_db_coll_size/plain")
+}
+
+func TestWithoutTimeseriesBuckets(t *testing.T) {
+	var items []collectionListItem
+	for _, doc := range []bson.M{
+		{"name": "weather", "type": "timeseries", "options": bson.M{"timeseries": bson.M{"timeField": "ts", "metaField": "sensor", "granularity": "seconds"}}},
+		{"name": "system.buckets.weather", "type": "collection", "options": bson.M{"validator": bson.M{"$jsonSchema": bson.M{"bsonType": "object"}}}},
+		{"name": "system.buckets.orphan", "type": "collection"},
+		{"name": "events", "type": "collection"},
+		{"name": "system.views", "type": "collection"},
+	} {
+		item := collectionListItem{}
+		testutils.MustDecodeBSON(t, doc, &item)
+		items = append(items, item)
+	}
+
+	var names []string
+	for _, item := range withoutTimeseriesBuckets(items) {
+		names = append(names, item.Name)
+	}
+	// the buckets of the time-series collection are reported by its collStats
+	assert.Equal(t, []string{"weather", "system.buckets.orphan", "events", "system.views"}, names)
+}
@@ -0,0 +1,297 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	electionsTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "election_metrics", "elections_total"),
+		"The total number of elections called and successfully won by this member by election reason",
+		[]string{"reason", "type"},
+		nil,
+	)
+	electionStepDownsCausedByHigherTermTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "election_metrics", "step_downs_caused_by_higher_term_total"),
+		"The total number of times this member stepped down because it saw a higher term",
+		nil,
+		nil,
+	)
+	electionCatchUpsTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "election_metrics", "catch_ups_total"),
+		"The total number of times this member as newly-elected primary had to catch up to the highest known oplog entry",
+		nil,
+		nil,
+	)
+	electionCatchUpsResultTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "election_metrics", "catch_ups_result_total"),
+		"The total number of primary catch-ups by outcome",
+		[]string{"result"},
+		nil,
+	)
+	electionAverageCatchUpOpsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "election_metrics", "average_catch_up_ops"),
+		"The average number of operations applied during the primary catch-ups",
+		nil,
+		nil,
+	)
+)
+
+var (
+	electionCandidateLastElectionDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "election_candidate_last_election_timestamp"),
+		"The timestamp of the last election this member called as candidate",
+		[]string{"set", "reason"},
+		nil,
+	)
+	electionCandidateTermDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "election_candidate_term"),
+		"The term of the last election this member called as candidate",
+		[]string{"set"},
+		nil,
+	)
+	electionCandidateVotesNeededDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "election_candidate_votes_needed"),
+		"The number of votes this member needed to win the last election",
+		[]string{"set"},
+		nil,
+	)
+	electionCandidatePriorityDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "election_candidate_priority"),
+		"The priority of this member at the last election it called",
+		[]string{"set"},
+		nil,
+	)
+	electionCandidateCatchUpOpsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "election_candidate_catch_up_ops"),
+		"The number of operations this member applied to catch up after winning the last election",
+		[]string{"set"},
+		nil,
+	)
+	electionCandidateWMajorityAvailableDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "election_candidate_wmajority_write_availability_timestamp"),
+		"The timestamp w:majority writes became available after the last election won by this member",
+		[]string{"set"},
+		nil,
+	)
+	electionParticipantVotedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "election_participant_voted_for_candidate"),
+		"Whether this member voted for the candidate of the last election it participated in",
+		[]string{"set", "candidate_member_id"},
+		nil,
+	)
+	electionParticipantTermDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "election_participant_term"),
+		"The term of the last election this member participated in",
+		[]string{"set"},
+		nil,
+	)
+	electionParticipantLastVoteDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "election_participant_last_vote_timestamp"),
+		"The timestamp this member voted in the last election it participated in",
+		[]string{"set"},
+		nil,
+	)
+)
+
+// ElectionReasonStats are the counters of a single election reason.
+type ElectionReasonStats struct {
+	Called     float64 `bson:"called"`
+	Successful float64 `bson:"successful"`
+}
+
+// ElectionMetricsStats are the serverStatus electionMetrics (4.2+).
+type ElectionMetricsStats struct {
+	StepUpCmd        *ElectionReasonStats `bson:"stepUpCmd"`
+	PriorityTakeover *ElectionReasonStats `bson:"priorityTakeover"`
+	CatchUpTakeover  *ElectionReasonStats `bson:"catchUpTakeover"`
+	ElectionTimeout  *ElectionReasonStats `bson:"electionTimeout"`
+	FreezeTimeout    *ElectionReasonStats `bson:"freezeTimeout"`
+
+	NumStepDownsCausedByHigherTerm float64 `bson:"numStepDownsCausedByHigherTerm"`
+	NumCatchUps                    float64 `bson:"numCatchUps"`
+
+	NumCatchUpsSucceeded                               float64 `bson:"numCatchUpsSucceeded"`
+	NumCatchUpsAlreadyCaughtUp                         float64 `bson:"numCatchUpsAlreadyCaughtUp"`
+	NumCatchUpsSkipped                                 float64 `bson:"numCatchUpsSkipped"`
+	NumCatchUpsTimedOut                                float64 `bson:"numCatchUpsTimedOut"`
+	NumCatchUpsFailedWithError                         float64 `bson:"numCatchUpsFailedWithError"`
+	NumCatchUpsFailedWithNewTerm                       float64 `bson:"numCatchUpsFailedWithNewTerm"`
+	NumCatchUpsFailedWithReplSetAbortPrimaryCatchUpCmd float64 `bson:"numCatchUpsFailedWithReplSetAbortPrimaryCatchUpCmd"`
+
+	AverageCatchUpOps *float64 `bson:"averageCatchUpOps"`
+}
+
+// Export exports the election metrics.
+func (stats *ElectionMetricsStats) Export(ch chan<- prometheus.Metric) {
+	for reason, val := range map[string]*ElectionReasonStats{
+		"stepUpCmd":        stats.StepUpCmd,
+		"priorityTakeover": stats.PriorityTakeover,
+		"catchUpTakeover":  stats.CatchUpTakeover,
+		"electionTimeout":  stats.ElectionTimeout,
+		"freezeTimeout":    stats.FreezeTimeout,
+	} {
+		if val != nil {
+			ch <- prometheus.MustNewConstMetric(electionsTotalDesc, prometheus.CounterValue, val.Called, reason, "called")
+			ch <- prometheus.MustNewConstMetric(electionsTotalDesc, prometheus.CounterValue, val.Successful, reason, "successful")
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(electionStepDownsCausedByHigherTermTotalDesc, prometheus.CounterValue, stats.NumStepDownsCausedByHigherTerm)
+	ch <- prometheus.MustNewConstMetric(electionCatchUpsTotalDesc, prometheus.CounterValue, stats.NumCatchUps)
+	for result, val := range map[string]float64{
+		"succeeded":                 stats.NumCatchUpsSucceeded,
+		"already_caught_up":         stats.NumCatchUpsAlreadyCaughtUp,
+		"skipped":                   stats.NumCatchUpsSkipped,
+		"timed_out":                 stats.NumCatchUpsTimedOut,
+		"failed_with_error":         stats.NumCatchUpsFailedWithError,
+		"failed_with_new_term":      stats.NumCatchUpsFailedWithNewTerm,
+		"failed_with_abort_command": stats.NumCatchUpsFailedWithReplSetAbortPrimaryCatchUpCmd,
+	} {
+		ch <- prometheus.MustNewConstMetric(electionCatchUpsResultTotalDesc, prometheus.CounterValue, val, result)
+	}
+
+	// new in version 4.2.1
+	if stats.AverageCatchUpOps != nil {
+		ch <- prometheus.MustNewConstMetric(electionAverageCatchUpOpsDesc, prometheus.GaugeValue, *stats.AverageCatchUpOps)
+	}
+}
+
+// Describe describes the election metrics for prometheus.
+func (stats *ElectionMetricsStats) Describe(ch chan<- *prometheus.Desc) {
+	ch <- electionsTotalDesc
+	ch <- electionStepDownsCausedByHigherTermTotalDesc
+	ch <- electionCatchUpsTotalDesc
+	ch <- electionCatchUpsResultTotalDesc
+	ch <- electionAverageCatchUpOpsDesc
+}
+
+// ElectionCandidateMetrics are the replSetGetStatus electionCandidateMetrics of the last election called by this member (4.2.1+).
+type ElectionCandidateMetrics struct {
+	LastElectionReason             string     `bson:"lastElectionReason"`
+	LastElectionDate               time.Time  `bson:"lastElectionDate"`
+	ElectionTerm                   float64    `bson:"electionTerm"`
+	NumVotesNeeded                 float64    `bson:"numVotesNeeded"`
+	PriorityAtElection             float64    `bson:"priorityAtElection"`
+	NumCatchUpOps                  *float64   `bson:"numCatchUpOps"`
+	WMajorityWriteAvailabilityDate *time.Time `bson:"wMajorityWriteAvailabilityDate"`
+}
+
+// Export exports the election candidate metrics of the replica set.
+func (metrics *ElectionCandidateMetrics) Export(ch chan<- prometheus.Metric, set string) {
+	ch <- prometheus.MustNewConstMetric(electionCandidateLastElectionDesc, prometheus.GaugeValue, float64(metrics.LastElectionDate.Unix()), set, metrics.LastElectionReason)
+	ch <- prometheus.MustNewConstMetric(electionCandidateTermDesc, prometheus.GaugeValue, metrics.ElectionTerm, set)
+	ch <- prometheus.MustNewConstMetric(electionCandidateVotesNeededDesc, prometheus.GaugeValue, metrics.NumVotesNeeded, set)
+	ch <- prometheus.MustNewConstMetric(electionCandidatePriorityDesc, prometheus.GaugeValue, metrics.PriorityAtElection, set)
+	if metrics.NumCatchUpOps != nil {
+		ch <- prometheus.MustNewConstMetric(electionCandidateCatchUpOpsDesc, prometheus.GaugeValue, *metrics.NumCatchUpOps, set)
+	}
+	if metrics.WMajorityWriteAvailabilityDate != nil {
+		ch <- prometheus.MustNewConstMetric(electionCandidateWMajorityAvailableDesc, prometheus.GaugeValue, float64(metrics.WMajorityWriteAvailabilityDate.Unix()), set)
+	}
+}
+
+// ElectionParticipantMetrics are the replSetGetStatus electionParticipantMetrics of the last election this member voted in (4.2.1+).
+type ElectionParticipantMetrics struct {
+	VotedForCandidate         bool      `bson:"votedForCandidate"`
+	ElectionTerm              float64   `bson:"electionTerm"`
+	LastVoteDate              time.Time `bson:"lastVoteDate"`
+	ElectionCandidateMemberID int32     `bson:"electionCandidateMemberId"`
+}
+
+// Export exports the election participant metrics of the replica set.
+func (metrics *ElectionParticipantMetrics) Export(ch chan<- prometheus.Metric, set string) {
+	voted := 0.0
+	if metrics.VotedForCandidate {
+		voted = 1
+	}
+	ch <- prometheus.MustNewConstMetric(electionParticipantVotedDesc, prometheus.GaugeValue, voted, set, strconv.Itoa(int(metrics.ElectionCandidateMemberID)))
+	ch <- prometheus.MustNewConstMetric(electionParticipantTermDesc, prometheus.GaugeValue, metrics.ElectionTerm, set)
+	ch <- prometheus.MustNewConstMetric(electionParticipantLastVoteDesc, prometheus.GaugeValue, float64(metrics.LastVoteDate.Unix()), set)
+}
+
+// PrimaryChangeTracker counts the primary changes observed between scrapes.
+// A change is either a new primary or a new term of a primary, counted once per scrape,
+// so that a failover and failback between two scrapes is still counted,
+// but the terms of the elections which did not elect a primary are not.
+type PrimaryChangeTracker struct {
+	m       sync.Mutex
+	primary map[string]string
+	term    map[string]int32
+	changes *prometheus.CounterVec
+}
+
+// NewPrimaryChangeTracker creates a new PrimaryChangeTracker.
+func NewPrimaryChangeTracker() *PrimaryChangeTracker {
+	return &PrimaryChangeTracker{
+		primary: make(map[string]string),
+		term:    make(map[string]int32),
+		changes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: subsystem,
+			Name:      "primary_changes_total",
+			Help:      "The total number of primary changes observed by the exporter, a new primary or a new term of the primary between two scrapes counting once",
+		}, []string{"set"}),
+	}
+}
+
+// Observe compares the replica set status with the previously observed one.
+func (tracker *PrimaryChangeTracker) Observe(status *ReplSetStatus) {
+	tracker.m.Lock()
+	defer tracker.m.Unlock()
+
+	var primary string
+	for _, member := range status.Members {
+		if member.StateStr == "PRIMARY" {
+			primary = member.Name
+			break
+		}
+	}
+	var term int32
+	if status.Term != nil {
+		term = *status.Term
+	}
+
+	// initialize the series on the first observation
+	counter := tracker.changes.WithLabelValues(status.Set)
+	lastPrimary, seen := tracker.primary[status.Set]
+	if lastTerm := tracker.term[status.Set]; seen && primary != "" {
+		// protocolVersion 0 has no term
+		if primary != lastPrimary || (term > 0 && lastTerm > 0 && term > lastTerm) {
+			counter.Inc()
+		}
+	}
+	// keep the last known primary during elections
+	if primary != "" {
+		tracker.primary[status.Set] = primary
+		tracker.term[status.Set] = term
+	}
+}
+
+// Export exports the primary changes.
+func (tracker *PrimaryChangeTracker) Export(ch chan<- prometheus.Metric) {
+	tracker.changes.Collect(ch)
+}
+
+// Describe describes the primary changes for prometheus.
+func (tracker *PrimaryChangeTracker) Describe(ch chan<- *prometheus.Desc) {
+	tracker.changes.Describe(ch)
+}
@@ -0,0 +1,148 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/percona/mongodb_exporter/testutils"
+)
+
+func TestElectionMetricsExport(t *testing.T) {
+	status := &ServerStatus{}
+	testutils.MustDecodeBSON(t, bson.M{
+		"electionMetrics": bson.M{
+			"stepUpCmd":                      bson.M{"called": int64(1), "successful": int64(1)},
+			"priorityTakeover":               bson.M{"called": int64(0), "successful": int64(0)},
+			"catchUpTakeover":                bson.M{"called": int64(0), "successful": int64(0)},
+			"electionTimeout":                bson.M{"called": int64(3), "successful": int64(2)},
+			"freezeTimeout":                  bson.M{"called": int64(0), "successful": int64(0)},
+			"numStepDownsCausedByHigherTerm": int64(1),
+			"numCatchUps":                    int64(2),
+			"numCatchUpsSucceeded":           int64(1),
+			"numCatchUpsAlreadyCaughtUp":     int64(1),
+			"averageCatchUpOps":              4.5,
+		},
+	}, status)
+	require.NotNil(t, status.ElectionMetrics)
+
+	values := testutils.CollectMetrics(status.ElectionMetrics.Export, "reason", "type", "result")
+
+	assert.Equal(t, 3.0, values["mongodb_mongod_election_metrics_elections_total/electionTimeout/called"])
+	assert.Equal(t, 2.0, values["mongodb_mongod_election_metrics_elections_total/electionTimeout/successful"])
+	assert.Equal(t, 1.0, values["mongodb_mongod_election_metrics_elections_total/stepUpCmd/successful"])
+	assert.Equal(t, 1.0, values["mongodb_mongod_election_metrics_step_downs_caused_by_higher_term_total"])
+	assert.Equal(t, 2.0, values["mongodb_mongod_election_metrics_catch_ups_total"])
+	assert.Equal(t, 1.0, values["mongodb_mongod_election_metrics_catch_ups_result_total/already_caught_up"])
+	assert.Equal(t, 4.5, values["mongodb_mongod_election_metrics_average_catch_up_ops"])
+}
+
+func TestReplSetStatusElectionMetrics(t *testing.T) {
+	elected := time.Unix(1561982400, 0)
+	values := exportReplSetStatus(t, bson.M{
+		"set":     "rs0",
+		"date":    elected,
+		"myState": int32(1),
+		"electionCandidateMetrics": bson.M{
+			"lastElectionReason":             "electionTimeout",
+			"lastElectionDate":               elected,
+			"electionTerm":                   int64(4),
+			"numVotesNeeded":                 int32(2),
+			"priorityAtElection":             1.0,
+			"numCatchUpOps":                  int64(7),
+			"wMajorityWriteAvailabilityDate": elected.Add(time.Second),
+		},
+		"electionParticipantMetrics": bson.M{
+			"votedForCandidate":         true,
+			"electionTerm":              int64(3),
+			"lastVoteDate":              elected.Add(-time.Hour),
+			"electionCandidateMemberId": int32(2),
+		},
+	})
+
+	assert.Equal(t, float64(elected.Unix()), values["mongodb_mongod_replset_election_candidate_last_election_timestamp"])
+	assert.Equal(t, 4.0, values["mongodb_mongod_replset_election_candidate_term"])
+	assert.Equal(t, 2.0, values["mongodb_mongod_replset_election_candidate_votes_needed"])
+	assert.Equal(t, 7.0, values["mongodb_mongod_replset_election_candidate_catch_up_ops"])
+	assert.Equal(t, float64(elected.Unix()+1), values["mongodb_mongod_replset_election_candidate_wmajority_write_availability_timestamp"])
+	assert.Equal(t, 1.0, values["mongodb_mongod_replset_election_participant_voted_for_candidate"])
+	assert.Equal(t, 3.0, values["mongodb_mongod_replset_election_participant_term"])
+}
+
+func TestPrimaryChangeTracker(t *testing.T) {
+	status := func(primary string, term int32) *ReplSetStatus {
+		s := &ReplSetStatus{Set: "rs0"}
+		if term > 0 {
+			s.Term = &term
+		}
+		for _, name := range []string{"rs1:27017", "rs2:27017"} {
+			stateStr := "SECONDARY"
+			if name == primary {
+				stateStr = "PRIMARY"
+			}
+			s.Members = append(s.Members, Member{Name: name, StateStr: stateStr})
+		}
+		return s
+	}
+	changes := func(tracker *PrimaryChangeTracker) float64 {
+		values := testutils.CollectMetrics(tracker.Export, "set")
+		require.Contains(t, values, "mongodb_mongod_replset_primary_changes_total/rs0")
+		return values["mongodb_mongod_replset_primary_changes_total/rs0"]
+	}
+
+	tracker := NewPrimaryChangeTracker()
+
+	// the first observation initializes the counter only
+	tracker.Observe(status("rs1:27017", 1))
+	assert.Equal(t, 0.0, changes(tracker))
+
+	tracker.Observe(status("rs1:27017", 1))
+	assert.Equal(t, 0.0, changes(tracker))
+
+	// failover to another member
+	tracker.Observe(status("rs2:27017", 2))
+	assert.Equal(t, 1.0, changes(tracker))
+
+	// failover and failback between two scrapes, or elections without a new primary: a single change
+	tracker.Observe(status("rs2:27017", 4))
+	assert.Equal(t, 2.0, changes(tracker))
+	tracker.Observe(status("rs2:27017", 9))
+	assert.Equal(t, 3.0, changes(tracker))
+
+	// election in progress, then the same primary is elected again
+	tracker.Observe(status("", 10))
+	assert.Equal(t, 3.0, changes(tracker))
+	tracker.Observe(status("rs2:27017", 10))
+	assert.Equal(t, 4.0, changes(tracker))
+}
+
+func TestPrimaryChangeTrackerWithoutTerm(t *testing.T) {
+	status := func(primary string) *ReplSetStatus {
+		return &ReplSetStatus{Set: "rs0", Members: []Member{{Name: primary, StateStr: "PRIMARY"}}}
+	}
+	tracker := NewPrimaryChangeTracker()
+
+	tracker.Observe(status("rs1:27017"))
+	tracker.Observe(status("rs1:27017"))
+	assert.Equal(t, 0.0, testutils.CollectMetrics(tracker.Export)["mongodb_mongod_replset_primary_changes_total"])
+
+	tracker.Observe(status("rs2:27017"))
+	assert.Equal(t, 1.0, testutils.CollectMetrics(tracker.Export)["mongodb_mongod_replset_primary_changes_total"])
+}
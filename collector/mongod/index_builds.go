@@ -0,0 +1,285 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/percona/mongodb_exporter/shared"
+)
+
+var (
+	indexBuildInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "", "index_build_info"),
+		"An in-progress index build, its phase and whether it is a two-phase build waiting for a commit quorum",
+		[]string{"db", "coll", "index", "phase", "two_phase"},
+		nil,
+	)
+	indexBuildProgressDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "", "index_build_progress"),
+		"The progress of the current phase of an in-progress index build",
+		[]string{"db", "coll", "index", "type"},
+		nil,
+	)
+	indexBuildElapsedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "", "index_build_elapsed_seconds"),
+		"The time an in-progress index build has been running",
+		[]string{"db", "coll", "index"},
+		nil,
+	)
+)
+
+var (
+	indexBuildsTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "index_builds", "total"),
+		"The total number of index builds started, or stopped because of insufficient disk space or data corruption",
+		[]string{"type"},
+		nil,
+	)
+	indexBuildsPhasesTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "index_builds", "phases_total"),
+		"The total number of times index builds reached each phase",
+		[]string{"phase"},
+		nil,
+	)
+)
+
+// IndexBuildsStats are the serverStatus indexBuilds (4.4+).
+type IndexBuildsStats struct {
+	Total                            float64            `bson:"total"`
+	KilledDueToInsufficientDiskSpace *float64           `bson:"killedDueToInsufficientDiskSpace"`
+	FailedDueToDataCorruption        *float64           `bson:"failedDueToDataCorruption"`
+	Phases                           map[string]float64 `bson:"phases"`
+}
+
+// Export exports the index builds stats.
+func (stats *IndexBuildsStats) Export(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(indexBuildsTotalDesc, prometheus.CounterValue, stats.Total, "started")
+	if stats.KilledDueToInsufficientDiskSpace != nil {
+		ch <- prometheus.MustNewConstMetric(indexBuildsTotalDesc, prometheus.CounterValue, *stats.KilledDueToInsufficientDiskSpace, "killed_insufficient_disk_space")
+	}
+	if stats.FailedDueToDataCorruption != nil {
+		ch <- prometheus.MustNewConstMetric(indexBuildsTotalDesc, prometheus.CounterValue, *stats.FailedDueToDataCorruption, "failed_data_corruption")
+	}
+	for phase, val := range stats.Phases {
+		ch <- prometheus.MustNewConstMetric(indexBuildsPhasesTotalDesc, prometheus.CounterValue, val, phase)
+	}
+}
+
+// Describe describes the index builds stats for prometheus.
+func (stats *IndexBuildsStats) Describe(ch chan<- *prometheus.Desc) {
+	ch <- indexBuildsTotalDesc
+	ch <- indexBuildsPhasesTotalDesc
+}
+
+// IndexBuildProgress is the progress of the current phase of an index build.
+type IndexBuildProgress struct {
+	Done  float64 `bson:"done"`
+	Total float64 `bson:"total"`
+}
+
+// IndexBuildOp is a $currentOp operation building indexes.
+type IndexBuildOp struct {
+	Desc             string              `bson:"desc"`
+	NS               string              `bson:"ns"`
+	Command          bson.Raw            `bson:"command"`
+	Msg              string              `bson:"msg"`
+	Progress         *IndexBuildProgress `bson:"progress,omitempty"`
+	MicrosecsRunning float64             `bson:"microsecs_running"`
+}
+
+// IndexBuildStats is an in-progress index build.
+type IndexBuildStats struct {
+	Database   string
+	Collection string
+	Index      string
+	Phase      string
+	TwoPhase   bool
+	Progress   *IndexBuildProgress
+	Elapsed    float64
+}
+
+// IndexBuildList contains the in-progress index builds
+type IndexBuildList struct {
+	Items []IndexBuildStats
+}
+
+// indexBuildProgressRE matches the progress at the end of the message of an index build, like "123/1000 12%".
+var indexBuildProgressRE = regexp.MustCompile(`:?\s*\d+/\d+\s*(\d+%)?\s*$`)
+
+// indexBuildPhase extracts the phase from the message of an index build operation,
+// like "Index Build: scanning collection Index Build: scanning collection: 123/1000 12%".
+func indexBuildPhase(msg string) string {
+	segments := strings.Split(msg, "Index Build")
+	phase := indexBuildProgressRE.ReplaceAllString(segments[len(segments)-1], "")
+	phase = strings.TrimPrefix(strings.TrimSpace(phase), "(background)")
+	phase = strings.ToLower(strings.Trim(phase, ": "))
+	if phase == "" {
+		return "building"
+	}
+	return strings.Join(strings.Fields(phase), "_")
+}
+
+// indexBuildsCoordinatorDesc is the description of the operations of the index builds coordinator,
+// building the indexes since 4.4.
+const indexBuildsCoordinatorDesc = "IndexBuildsCoordinatorMongod"
+
+// Builds returns the index builds of the operation.
+// Since 4.4 every build of the coordinator on a replica set is a two-phase build,
+// also without a commitQuorum in the command.
+func (op *IndexBuildOp) Builds(replSet bool) []IndexBuildStats {
+	coll, ok := op.Command.Lookup("createIndexes").StringValueOK()
+	if !ok {
+		return nil
+	}
+	db, ok := op.Command.Lookup("$db").StringValueOK()
+	if !ok {
+		db = strings.SplitN(op.NS, ".", 2)[0]
+	}
+	_, err := op.Command.LookupErr("commitQuorum")
+	twoPhase := err == nil || (replSet && strings.HasPrefix(op.Desc, indexBuildsCoordinatorDesc))
+
+	indexes, ok := op.Command.Lookup("indexes").ArrayOK()
+	if !ok {
+		return nil
+	}
+	values, err := indexes.Values()
+	if err != nil {
+		return nil
+	}
+
+	var builds []IndexBuildStats
+	for _, value := range values {
+		doc, ok := value.DocumentOK()
+		if !ok {
+			continue
+		}
+		name, _ := doc.Lookup("name").StringValueOK()
+		builds = append(builds, IndexBuildStats{
+			Database:   db,
+			Collection: coll,
+			Index:      name,
+			Phase:      indexBuildPhase(op.Msg),
+			TwoPhase:   twoPhase,
+			Progress:   op.Progress,
+			Elapsed:    op.MicrosecsRunning / 1e6,
+		})
+	}
+	return builds
+}
+
+// NewIndexBuildList merges the index builds of the operations.
+// Since 4.2 the client createIndexes operation waits for a separate build operation reporting the phase and progress,
+// so the build with a progress or phase is preferred. A build is two-phase when either operation says so.
+func NewIndexBuildList(ops []IndexBuildOp, replSet bool) *IndexBuildList {
+	list := &IndexBuildList{}
+	positions := make(map[string]int)
+	for i := range ops {
+		for _, build := range ops[i].Builds(replSet) {
+			key := build.Database + "." + build.Collection + "." + build.Index
+			pos, seen := positions[key]
+			switch {
+			case !seen:
+				positions[key] = len(list.Items)
+				list.Items = append(list.Items, build)
+			case list.Items[pos].Progress == nil && (build.Progress != nil || list.Items[pos].Phase == "building"):
+				// keep the two-phase flag and elapsed time of the client operation
+				build.TwoPhase = build.TwoPhase || list.Items[pos].TwoPhase
+				if list.Items[pos].Elapsed > build.Elapsed {
+					build.Elapsed = list.Items[pos].Elapsed
+				}
+				list.Items[pos] = build
+			default:
+				list.Items[pos].TwoPhase = list.Items[pos].TwoPhase || build.TwoPhase
+			}
+		}
+	}
+	return list
+}
+
+// Export exports the in-progress index builds.
+func (list *IndexBuildList) Export(ch chan<- prometheus.Metric) {
+	for _, build := range list.Items {
+		ch <- prometheus.MustNewConstMetric(indexBuildInfoDesc, prometheus.GaugeValue, 1,
+			build.Database, build.Collection, build.Index, build.Phase, strconv.FormatBool(build.TwoPhase))
+		ch <- prometheus.MustNewConstMetric(indexBuildElapsedDesc, prometheus.GaugeValue, build.Elapsed, build.Database, build.Collection, build.Index)
+		if build.Progress != nil {
+			ch <- prometheus.MustNewConstMetric(indexBuildProgressDesc, prometheus.GaugeValue, build.Progress.Done, build.Database, build.Collection, build.Index, "done")
+			ch <- prometheus.MustNewConstMetric(indexBuildProgressDesc, prometheus.GaugeValue, build.Progress.Total, build.Database, build.Collection, build.Index, "total")
+		}
+	}
+}
+
+// Describe describes the index build metrics for prometheus.
+func (list *IndexBuildList) Describe(ch chan<- *prometheus.Desc) {
+	ch <- indexBuildInfoDesc
+	ch <- indexBuildProgressDesc
+	ch <- indexBuildElapsedDesc
+}
+
+var (
+	logSuppressIB = make(map[string]bool)
+)
+
+// GetIndexBuildList returns the in-progress index builds reported by $currentOp (3.6+).
+func GetIndexBuildList(client *mongo.Client, replSet bool) *IndexBuildList {
+	pipeline := []bson.M{
+		{"$currentOp": bson.M{"allUsers": true, "idleConnections": false}},
+		{"$match": bson.M{"command.createIndexes": bson.M{"$exists": true}}},
+	}
+	cmd := bson.D{
+		{Key: "aggregate", Value: 1},
+		{Key: "pipeline", Value: pipeline},
+		{Key: "cursor", Value: bson.M{}},
+		{Key: "comment", Value: shared.GetCallerLocation()},
+	}
+	c, err := client.Database("admin").RunCommandCursor(context.TODO(), cmd)
+	if err != nil {
+		if !logSuppressIB[""] {
+			log.Errorf("Failed to get index builds from $currentOp: %s. This log message will be suppressed from now.", err)
+			logSuppressIB[""] = true
+		}
+		return nil
+	}
+	delete(logSuppressIB, "")
+	defer func() {
+		if err := c.Close(context.TODO()); err != nil {
+			log.Errorf("Could not close $currentOp cursor, reason: %v", err)
+		}
+	}()
+
+	var ops []IndexBuildOp
+	for c.Next(context.TODO()) {
+		op := IndexBuildOp{}
+		if err := c.Decode(&op); err != nil {
+			log.Errorf("Failed to decode $currentOp operation: %s.", err)
+			continue
+		}
+		ops = append(ops, op)
+	}
+	if err := c.Err(); err != nil {
+		log.Errorf("Failed to get index builds from $currentOp: %s.", err)
+		return nil
+	}
+	return NewIndexBuildList(ops, replSet)
+}
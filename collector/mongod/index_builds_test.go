@@ -0,0 +1,160 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/percona/mongodb_exporter/testutils"
+)
+
+func decodeIndexBuildOp(t *testing.T, doc bson.M) IndexBuildOp {
+	op := IndexBuildOp{}
+	testutils.MustDecodeBSON(t, doc, &op)
+	return op
+}
+
+func TestIndexBuildPhase(t *testing.T) {
+	for msg, expected := range map[string]string{
+		"": "building",
+		"Index Build: scanning collection Index Build: scanning collection: 123/1000 12%": "scanning_collection",
+		"Index Build: inserting keys from external sorter into index: 50/100 50%":         "inserting_keys_from_external_sorter_into_index",
+		"Index Build: draining writes received during build":                              "draining_writes_received_during_build",
+		"Index Build (background): 1234/5678 21%":                                         "building",
+		"Index Build: 1234/5678 21%":                                                      "building",
+	} {
+		assert.Equal(t, expected, indexBuildPhase(msg), msg)
+	}
+}
+
+func TestIndexBuildList(t *testing.T) {
+	indexes := []bson.M{
+		{"key": bson.M{"a": int32(1)}, "name": "a_1"},
+		{"key": bson.M{"b": int32(1)}, "name": "b_1"},
+	}
+	ops := []IndexBuildOp{
+		// the client operation waiting for the build
+		decodeIndexBuildOp(t, bson.M{
+			"desc":              "conn12",
+			"ns":                "test.$cmd",
+			"command":           bson.M{"createIndexes": "coll", "indexes": indexes, "commitQuorum": "votingMembers", "$db": "test"},
+			"microsecs_running": int64(90000000),
+		}),
+		// the build operation
+		decodeIndexBuildOp(t, bson.M{
+			"desc":              "IndexBuildsCoordinatorMongod-0",
+			"ns":                "test.coll",
+			"command":           bson.M{"createIndexes": "coll", "indexes": indexes},
+			"msg":               "Index Build: scanning collection Index Build: scanning collection: 250/1000 25%",
+			"progress":          bson.M{"done": int32(250), "total": int32(1000)},
+			"microsecs_running": int64(89000000),
+		}),
+		// a pre-4.2 background build
+		decodeIndexBuildOp(t, bson.M{
+			"desc":              "conn7",
+			"ns":                "other.$cmd",
+			"command":           bson.M{"createIndexes": "events", "indexes": []bson.M{{"key": bson.M{"ts": int32(1)}, "name": "ts_1", "background": true}}, "$db": "other"},
+			"msg":               "Index Build (background): 10/40 25%",
+			"progress":          bson.M{"done": int64(10), "total": int64(40)},
+			"microsecs_running": int64(2500000),
+		}),
+	}
+
+	list := NewIndexBuildList(ops, false)
+	require.Len(t, list.Items, 3)
+	assert.Equal(t, IndexBuildStats{
+		Database:   "test",
+		Collection: "coll",
+		Index:      "a_1",
+		Phase:      "scanning_collection",
+		TwoPhase:   true,
+		Progress:   &IndexBuildProgress{Done: 250, Total: 1000},
+		Elapsed:    90,
+	}, list.Items[0])
+	assert.Equal(t, "b_1", list.Items[1].Index)
+
+	values := testutils.CollectMetrics(list.Export, "db", "coll", "index", "phase", "two_phase", "type")
+
+	assert.Equal(t, 1.0, values["mongodb_mongod_index_build_info/test/coll/a_1/scanning_collection/true"])
+	assert.Equal(t, 250.0, values["mongodb_mongod_index_build_progress/test/coll/b_1/done"])
+	assert.Equal(t, 1000.0, values["mongodb_mongod_index_build_progress/test/coll/b_1/total"])
+	assert.Equal(t, 1.0, values["mongodb_mongod_index_build_info/other/events/ts_1/building/false"])
+	assert.Equal(t, 2.5, values["mongodb_mongod_index_build_elapsed_seconds/other/events/ts_1"])
+}
+
+func TestIndexBuildListDefaultCommitQuorum(t *testing.T) {
+	indexes := []bson.M{{"key": bson.M{"a": int32(1)}, "name": "a_1"}}
+	ops := []IndexBuildOp{
+		// a 4.4 client operation with the default commit quorum, not echoed in the command
+		decodeIndexBuildOp(t, bson.M{
+			"desc":              "conn12",
+			"ns":                "test.$cmd",
+			"command":           bson.M{"createIndexes": "coll", "indexes": indexes, "$db": "test"},
+			"microsecs_running": int64(3000000),
+		}),
+		decodeIndexBuildOp(t, bson.M{
+			"desc":              "IndexBuildsCoordinatorMongod-1",
+			"ns":                "test.coll",
+			"command":           bson.M{"createIndexes": "coll", "indexes": indexes},
+			"msg":               "Index Build: draining writes received during build",
+			"microsecs_running": int64(2000000),
+		}),
+	}
+
+	// every index build of the coordinator on a replica set is a two-phase build
+	list := NewIndexBuildList(ops, true)
+	require.Len(t, list.Items, 1)
+	assert.True(t, list.Items[0].TwoPhase)
+	assert.Equal(t, "draining_writes_received_during_build", list.Items[0].Phase)
+
+	// a standalone builds the indexes in a single phase
+	list = NewIndexBuildList(ops, false)
+	require.Len(t, list.Items, 1)
+	assert.False(t, list.Items[0].TwoPhase)
+
+	// a build without a coordinator operation
+	list = NewIndexBuildList(ops[:1], true)
+	require.Len(t, list.Items, 1)
+	assert.False(t, list.Items[0].TwoPhase)
+}
+
+func TestIndexBuildsStats(t *testing.T) {
+	status := &ServerStatus{}
+	testutils.MustDecodeBSON(t, bson.M{"indexBuilds": bson.M{
+		"total":                            int64(4),
+		"killedDueToInsufficientDiskSpace": int64(1),
+		"failedDueToDataCorruption":        int64(0),
+		"phases": bson.M{
+			"scanCollection":      int64(4),
+			"waitForCommitQuorum": int64(3),
+			"commitIndexBuild":    int64(2),
+		},
+	}}, status)
+	require.NotNil(t, status.IndexBuilds)
+
+	values := testutils.CollectMetrics(status.IndexBuilds.Export, "type", "phase")
+	assert.Equal(t, map[string]float64{
+		"mongodb_mongod_index_builds_total/started":                        4,
+		"mongodb_mongod_index_builds_total/killed_insufficient_disk_space": 1,
+		"mongodb_mongod_index_builds_total/failed_data_corruption":         0,
+		"mongodb_mongod_index_builds_phases_total/scanCollection":          4,
+		"mongodb_mongod_index_builds_phases_total/waitForCommitQuorum":     3,
+		"mongodb_mongod_index_builds_phases_total/commitIndexBuild":        2,
+	}, values)
+}
@@ -0,0 +1,146 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"context"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/percona/mongodb_exporter/shared"
+)
+
+var (
+	indexWTCacheBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "db_coll_index", "wiredtiger_cache_bytes"),
+		"The size in bytes of the index data currently in the WiredTiger Cache",
+		[]string{"db", "coll", "index"},
+		nil,
+	)
+	indexWTCachePagesTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "db_coll_index", "wiredtiger_cache_pages_total"),
+		"The total number of index pages read into/written from the WiredTiger Cache",
+		[]string{"db", "coll", "index", "type"},
+		nil,
+	)
+	indexWTReusableBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "db_coll_index", "wiredtiger_blockmanager_reusable_bytes"),
+		"The size in bytes of the index file available for reuse",
+		[]string{"db", "coll", "index"},
+		nil,
+	)
+	indexPrefixCompressionDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "db_coll_index", "prefix_compression"),
+		"Whether the index was created with WiredTiger prefix compression",
+		[]string{"db", "coll", "index"},
+		nil,
+	)
+)
+
+// IndexDetailsStats represents the WiredTiger stats of an index.
+type IndexDetailsStats struct {
+	Database   string
+	Collection string
+	Index      string
+	Stats      CollectionWTStats
+}
+
+// IndexDetailsStatList contains the WiredTiger stats of all indexes
+type IndexDetailsStatList struct {
+	Members []IndexDetailsStats
+}
+
+// PrefixCompression returns true if the table was created with prefix_compression=true.
+func (stats *CollectionWTStats) PrefixCompression() bool {
+	for _, option := range strings.Split(stats.CreationString, ",") {
+		if option == "prefix_compression=true" {
+			return true
+		}
+	}
+	return false
+}
+
+// Export exports index details to prometheus
+func (indexDetailsList *IndexDetailsStatList) Export(ch chan<- prometheus.Metric) {
+	for _, member := range indexDetailsList.Members {
+		ls := []string{member.Database, member.Collection, member.Index}
+		ch <- prometheus.MustNewConstMetric(indexWTCacheBytesDesc, prometheus.GaugeValue, member.Stats.Cache.BytesInCache, ls...)
+		ch <- prometheus.MustNewConstMetric(indexWTCachePagesTotalDesc, prometheus.CounterValue, member.Stats.Cache.PagesReadInto, append(ls, "read")...)
+		ch <- prometheus.MustNewConstMetric(indexWTCachePagesTotalDesc, prometheus.CounterValue, member.Stats.Cache.PagesWrittenFrom, append(ls, "written")...)
+		ch <- prometheus.MustNewConstMetric(indexWTReusableBytesDesc, prometheus.GaugeValue, member.Stats.BlockManager.FileBytesAvailableForReuse, ls...)
+		prefixCompression := 0.0
+		if member.Stats.PrefixCompression() {
+			prefixCompression = 1
+		}
+		ch <- prometheus.MustNewConstMetric(indexPrefixCompressionDesc, prometheus.GaugeValue, prefixCompression, ls...)
+	}
+}
+
+// Describe describes index details for prometheus
+func (indexDetailsList *IndexDetailsStatList) Describe(ch chan<- *prometheus.Desc) {
+	ch <- indexWTCacheBytesDesc
+	ch <- indexWTCachePagesTotalDesc
+	ch <- indexWTReusableBytesDesc
+	ch <- indexPrefixCompressionDesc
+}
+
+var (
+	logSuppressID = make(map[string]bool)
+)
+
+// GetIndexDetailsStatList returns the WiredTiger stats of the indexes of the collections selected by filter
+func GetIndexDetailsStatList(client *mongo.Client, filter shared.NamespaceFilter) *IndexDetailsStatList {
+	namespaces, err := shared.ListCollectionNamespaces(client, filter)
+	if err != nil {
+		_, logSFound := logSuppressID[""]
+		if !logSFound {
+			log.Errorf("%s. Index details will not be collected. This log message will be suppressed from now.", err)
+			logSuppressID[""] = true
+		}
+		return nil
+	}
+	delete(logSuppressID, "")
+
+	indexDetailsList := &IndexDetailsStatList{}
+	for _, ns := range namespaces {
+		collStats := struct {
+			IndexDetails map[string]CollectionWTStats `bson:"indexDetails"`
+		}{}
+		key := ns.Database + "." + ns.Collection
+		err := client.Database(ns.Database).RunCommand(context.TODO(), bson.D{{Key: "collStats", Value: ns.Collection}, {Key: "scale", Value: 1}}).Decode(&collStats)
+		if err != nil {
+			_, logSFound := logSuppressID[key]
+			if !logSFound {
+				log.Errorf("%s. Index details will not be collected for this collection. This log message will be suppressed from now.", err)
+				logSuppressID[key] = true
+			}
+			continue
+		}
+		delete(logSuppressID, key)
+		for index, stats := range collStats.IndexDetails {
+			indexDetailsList.Members = append(indexDetailsList.Members, IndexDetailsStats{
+				Database:   ns.Database,
+				Collection: ns.Collection,
+				Index:      index,
+				Stats:      stats,
+			})
+		}
+	}
+	return indexDetailsList
+}
@@ -0,0 +1,51 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/percona/mongodb_exporter/testutils"
+)
+
+func TestIndexDetailsExport(t *testing.T) {
+	var stats CollectionWTStats
+	testutils.MustDecodeBSON(t, bson.M{
+		"creationString": "allocation_size=4KB,block_compressor=,prefix_compression=true,prefix_compression_min=4",
+		"block-manager":  bson.M{"file bytes available for reuse": int32(8192)},
+		"cache": bson.M{
+			"bytes currently in the cache": int64(1024),
+			"pages read into cache":        int64(3),
+			"pages written from cache":     int64(4),
+		},
+	}, &stats)
+
+	list := &IndexDetailsStatList{Members: []IndexDetailsStats{
+		{Database: "test", Collection: "coll", Index: "a_1", Stats: stats},
+		{Database: "test", Collection: "coll", Index: "_id_", Stats: CollectionWTStats{CreationString: "prefix_compression=false"}},
+	}}
+	values := testutils.CollectMetrics(list.Export, "index", "type")
+
+	assert.Equal(t, 1024.0, values["mongodb_mongod_db_coll_index_wiredtiger_cache_bytes/a_1"])
+	assert.Equal(t, 3.0, values["mongodb_mongod_db_coll_index_wiredtiger_cache_pages_total/a_1/read"])
+	assert.Equal(t, 4.0, values["mongodb_mongod_db_coll_index_wiredtiger_cache_pages_total/a_1/written"])
+	assert.Equal(t, 8192.0, values["mongodb_mongod_db_coll_index_wiredtiger_blockmanager_reusable_bytes/a_1"])
+	assert.Equal(t, 1.0, values["mongodb_mongod_db_coll_index_prefix_compression/a_1"])
+	assert.Equal(t, 0.0, values["mongodb_mongod_db_coll_index_prefix_compression/_id_"])
+	assert.Len(t, values, 10)
+}
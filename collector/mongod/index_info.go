@@ -0,0 +1,217 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/percona/mongodb_exporter/shared"
+)
+
+var (
+	indexInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "", "index_info"),
+		"The definition of the index",
+		[]string{"db", "coll", "index", "unique", "sparse", "partial", "ttl", "hidden", "key_pattern"},
+		nil,
+	)
+	indexTTLExpireAfterSecondsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "", "index_ttl_expire_after_seconds"),
+		"The expireAfterSeconds of the TTL index",
+		[]string{"db", "coll", "index"},
+		nil,
+	)
+	collectionIndexesByTypeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "db_coll", "indexes_by_type"),
+		"The number of indexes of the collection by index type",
+		[]string{"db", "coll", "type"},
+		nil,
+	)
+)
+
+// indexTypes are always exported by collectionIndexesByTypeDesc, even if the collection has no such index.
+var indexTypes = []string{"regular", "text", "2dsphere", "hashed", "wildcard"}
+
+// IndexInfo represents an index definition returned by listIndexes
+type IndexInfo struct {
+	Name                    string        `bson:"name"`
+	Key                     bson.Raw      `bson:"key"`
+	Unique                  bson.RawValue `bson:"unique"`
+	Sparse                  bson.RawValue `bson:"sparse"`
+	Hidden                  bson.RawValue `bson:"hidden"`
+	PartialFilterExpression bson.Raw      `bson:"partialFilterExpression"`
+	ExpireAfterSeconds      bson.RawValue `bson:"expireAfterSeconds"`
+	Database                string
+	Collection              string
+}
+
+// IndexInfoList contains the definitions of all indexes
+type IndexInfoList struct {
+	Items []IndexInfo
+}
+
+// TTL returns the expireAfterSeconds of a TTL index.
+func (info *IndexInfo) TTL() (float64, bool) {
+	return rawValueToFloat64(info.ExpireAfterSeconds)
+}
+
+// Type returns the index type derived from its key pattern:
+// regular, text, 2dsphere, 2d, geoHaystack, hashed or wildcard.
+func (info *IndexInfo) Type() string {
+	elements, _ := info.Key.Elements()
+	for _, e := range elements {
+		if strings.HasSuffix(e.Key(), "$**") {
+			return "wildcard"
+		}
+		if s, ok := e.Value().StringValueOK(); ok {
+			return s
+		}
+	}
+	return "regular"
+}
+
+// KeyPattern formats the index key pattern, e.g. {a:1,b:-1}.
+func (info *IndexInfo) KeyPattern() string {
+	elements, _ := info.Key.Elements()
+	fields := make([]string, 0, len(elements))
+	for _, e := range elements {
+		value := e.Value().String()
+		if f, ok := rawValueToFloat64(e.Value()); ok {
+			value = strconv.FormatFloat(f, 'f', -1, 64)
+		}
+		fields = append(fields, e.Key()+":"+value)
+	}
+	return "{" + strings.Join(fields, ",") + "}"
+}
+
+// rawValueIsTrue returns true for a true boolean or a non-zero number (as used by old index definitions).
+func rawValueIsTrue(v bson.RawValue) bool {
+	if v.Type == bsontype.Boolean {
+		return v.Boolean()
+	}
+	f, ok := rawValueToFloat64(v)
+	return ok && f != 0
+}
+
+// Export exports index definitions to prometheus
+func (indexInfoList *IndexInfoList) Export(ch chan<- prometheus.Metric) {
+	type collection struct{ db, coll string }
+	byType := make(map[collection]map[string]float64)
+	for _, info := range indexInfoList.Items {
+		expireAfterSeconds, ttl := info.TTL()
+		ch <- prometheus.MustNewConstMetric(indexInfoDesc, prometheus.GaugeValue, 1,
+			info.Database, info.Collection, info.Name,
+			strconv.FormatBool(rawValueIsTrue(info.Unique)),
+			strconv.FormatBool(rawValueIsTrue(info.Sparse)),
+			strconv.FormatBool(info.PartialFilterExpression != nil),
+			strconv.FormatBool(ttl),
+			strconv.FormatBool(rawValueIsTrue(info.Hidden)),
+			info.KeyPattern(),
+		)
+		if ttl {
+			ch <- prometheus.MustNewConstMetric(indexTTLExpireAfterSecondsDesc, prometheus.GaugeValue, expireAfterSeconds, info.Database, info.Collection, info.Name)
+		}
+
+		c := collection{info.Database, info.Collection}
+		if byType[c] == nil {
+			byType[c] = make(map[string]float64)
+			for _, t := range indexTypes {
+				byType[c][t] = 0
+			}
+		}
+		byType[c][info.Type()]++
+	}
+	for c, types := range byType {
+		for t, count := range types {
+			ch <- prometheus.MustNewConstMetric(collectionIndexesByTypeDesc, prometheus.GaugeValue, count, c.db, c.coll, t)
+		}
+	}
+}
+
+// Describe describes index definitions for prometheus
+func (indexInfoList *IndexInfoList) Describe(ch chan<- *prometheus.Desc) {
+	ch <- indexInfoDesc
+	ch <- indexTTLExpireAfterSecondsDesc
+	ch <- collectionIndexesByTypeDesc
+}
+
+// listIndexes returns the index definitions of a collection.
+func listIndexes(client *mongo.Client, db, coll string) ([]IndexInfo, error) {
+	c, err := client.Database(db).Collection(coll).Indexes().List(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := c.Close(context.TODO()); err != nil {
+			log.Errorf("Could not close listIndexes cursor, reason: %v", err)
+		}
+	}()
+
+	var indexes []IndexInfo
+	for c.Next(context.TODO()) {
+		info := IndexInfo{}
+		if err := c.Decode(&info); err != nil {
+			log.Error(err)
+			continue
+		}
+		info.Database = db
+		info.Collection = coll
+		indexes = append(indexes, info)
+	}
+	return indexes, c.Err()
+}
+
+var (
+	logSuppressII = make(map[string]bool)
+)
+
+// GetIndexInfoList returns the index definitions of the collections selected by filter
+func GetIndexInfoList(client *mongo.Client, filter shared.NamespaceFilter) *IndexInfoList {
+	namespaces, err := shared.ListCollectionNamespaces(client, filter)
+	if err != nil {
+		_, logSFound := logSuppressII[""]
+		if !logSFound {
+			log.Errorf("%s. Index definitions will not be collected. This log message will be suppressed from now.", err)
+			logSuppressII[""] = true
+		}
+		return nil
+	}
+	delete(logSuppressII, "")
+
+	indexInfoList := &IndexInfoList{}
+	for _, ns := range namespaces {
+		key := ns.Database + "." + ns.Collection
+		indexes, err := listIndexes(client, ns.Database, ns.Collection)
+		if err != nil {
+			_, logSFound := logSuppressII[key]
+			if !logSFound {
+				log.Errorf("%s. Index definitions will not be collected for this collection. This log message will be suppressed from now.", err)
+				logSuppressII[key] = true
+			}
+			continue
+		}
+		delete(logSuppressII, key)
+		indexInfoList.Items = append(indexInfoList.Items, indexes...)
+	}
+	return indexInfoList
+}
@@ -0,0 +1,83 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"testing"
+
+	"github.com/percona/exporter_shared/helpers"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/percona/mongodb_exporter/testutils"
+)
+
+func decodeIndexInfo(t *testing.T, doc bson.D) IndexInfo {
+	info := IndexInfo{Database: "test", Collection: "coll"}
+	testutils.MustDecodeBSON(t, doc, &info)
+	return info
+}
+
+func TestIndexInfoExport(t *testing.T) {
+	list := &IndexInfoList{Items: []IndexInfo{
+		decodeIndexInfo(t, bson.D{{Key: "v", Value: int32(2)}, {Key: "key", Value: bson.D{{Key: "_id", Value: int32(1)}}}, {Key: "name", Value: "_id_"}}),
+		decodeIndexInfo(t, bson.D{{Key: "key", Value: bson.D{{Key: "a", Value: int32(1)}, {Key: "b", Value: float64(-1)}}}, {Key: "name", Value: "a_1_b_-1"}, {Key: "unique", Value: true}, {Key: "partialFilterExpression", Value: bson.D{{Key: "a", Value: bson.D{{Key: "$gt", Value: int32(5)}}}}}}),
+		decodeIndexInfo(t, bson.D{{Key: "key", Value: bson.D{{Key: "createdAt", Value: int32(1)}}}, {Key: "name", Value: "createdAt_1"}, {Key: "expireAfterSeconds", Value: int32(3600)}, {Key: "sparse", Value: int32(1)}}),
+		decodeIndexInfo(t, bson.D{{Key: "key", Value: bson.D{{Key: "_fts", Value: "text"}, {Key: "_ftsx", Value: int32(1)}}}, {Key: "name", Value: "body_text"}}),
+		decodeIndexInfo(t, bson.D{{Key: "key", Value: bson.D{{Key: "attrs.$**", Value: int32(1)}}}, {Key: "name", Value: "attrs.$**_1"}, {Key: "hidden", Value: true}}),
+		decodeIndexInfo(t, bson.D{{Key: "key", Value: bson.D{{Key: "user", Value: "hashed"}}}, {Key: "name", Value: "user_hashed"}}),
+	}}
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		list.Export(ch)
+		close(ch)
+	}()
+	info := make(map[string]map[string]string)
+	values := make(map[string]float64)
+	for m := range ch {
+		metric := helpers.ReadMetric(m)
+		switch metric.Name {
+		case "mongodb_mongod_index_info":
+			info[metric.Labels["index"]] = metric.Labels
+		case "mongodb_mongod_db_coll_indexes_by_type":
+			values[metric.Name+"/"+metric.Labels["type"]] = metric.Value
+		default:
+			values[metric.Name+"/"+metric.Labels["index"]] = metric.Value
+		}
+	}
+
+	require.Len(t, info, 6)
+	assert.Equal(t, map[string]string{
+		"db": "test", "coll": "coll", "index": "a_1_b_-1",
+		"unique": "true", "sparse": "false", "partial": "true", "ttl": "false", "hidden": "false",
+		"key_pattern": "{a:1,b:-1}",
+	}, info["a_1_b_-1"])
+	assert.Equal(t, "true", info["createdAt_1"]["ttl"])
+	assert.Equal(t, "true", info["createdAt_1"]["sparse"])
+	assert.Equal(t, "true", info["attrs.$**_1"]["hidden"])
+	assert.Equal(t, `{_fts:"text",_ftsx:1}`, info["body_text"]["key_pattern"])
+
+	assert.Equal(t, map[string]float64{
+		"mongodb_mongod_index_ttl_expire_after_seconds/createdAt_1": 3600,
+		"mongodb_mongod_db_coll_indexes_by_type/regular":            3,
+		"mongodb_mongod_db_coll_indexes_by_type/text":               1,
+		"mongodb_mongod_db_coll_indexes_by_type/2dsphere":           0,
+		"mongodb_mongod_db_coll_indexes_by_type/hashed":             1,
+		"mongodb_mongod_db_coll_indexes_by_type/wildcard":           1,
+	}, values)
+}
@@ -2,6 +2,7 @@ package mongod
 
 import (
 	"context"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/log"
@@ -11,11 +12,30 @@ import (
 )
 
 var (
-	indexUsage = prometheus.NewCounterVec(prometheus.CounterOpts{
-		Namespace: Namespace,
-		Name:      "index_usage_count",
-		Help:      "Contains a usage count of each index",
-	}, []string{"collection", "db", "index"})
+	indexUsageDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "", "index_usage_count"),
+		"Contains a usage count of each index, by shard and host through mongos",
+		[]string{"collection", "db", "index", "shard", "host"},
+		nil,
+	)
+	indexUsageSinceDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "", "index_usage_since_seconds"),
+		"The unix timestamp usage counting of the index started at, i.e. index creation or last server restart",
+		[]string{"collection", "db", "index", "shard", "host"},
+		nil,
+	)
+	indexUnusedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "", "index_unused"),
+		"Whether the index has not been used since a time older than the configured unused index age",
+		[]string{"collection", "db", "index", "shard", "host"},
+		nil,
+	)
+	indexUnusedReclaimableBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "", "index_unused_reclaimable_bytes"),
+		"The total size in bytes of the indexes of the collection unused on every shard, summed over the shards through mongos",
+		[]string{"collection", "db"},
+		nil,
+	)
 )
 
 // IndexStatsList represents index usage information
@@ -27,40 +47,83 @@ type IndexStatsList struct {
 type IndexUsageStats struct {
 	Name       string         `bson:"name"`
 	Accesses   IndexUsageInfo `bson:"accesses"`
+	Host       string         `bson:"host"`
+	Shard      string         `bson:"shard"`
 	Database   string
 	Collection string
+	// Unused is set if the index has no access since a time older than the unused index age.
+	Unused bool
+	// Size is the index size from collStats, only set for unused indexes.
+	Size float64
 }
 
 // IndexUsageInfo represents a single index stats of an Index
 type IndexUsageInfo struct {
-	Ops float64 `bson:"ops"`
+	Ops   float64   `bson:"ops"`
+	Since time.Time `bson:"since"`
 }
 
-// Export exports database stats to prometheus
+// isUnused returns true if the index has no access since a time older than unusedAge.
+// The _id index is never reported as it can't be dropped.
+func (indexStat *IndexUsageStats) isUnused(unusedAge time.Duration, now time.Time) bool {
+	if indexStat.Name == "_id_" || indexStat.Accesses.Ops > 0 || indexStat.Accesses.Since.IsZero() {
+		return false
+	}
+	return now.Sub(indexStat.Accesses.Since) >= unusedAge
+}
+
+// Export exports database stats to prometheus.
+// $indexStats run through mongos returns one document per shard for every index.
 func (indexStats *IndexStatsList) Export(ch chan<- prometheus.Metric) {
-	indexUsage.Reset()
+	type indexKey struct{ coll, db, index string }
+	// an index can only be dropped to reclaim its size if it is unused on every shard
+	unused := make(map[indexKey]bool)
+	sizes := make(map[indexKey]float64)
 	for _, indexStat := range indexStats.Items {
-		ls := prometheus.Labels{
-			"db":         indexStat.Database,
-			"collection": indexStat.Collection,
-			"index":      indexStat.Name,
+		ls := []string{indexStat.Collection, indexStat.Database, indexStat.Name, indexStat.Shard, indexStat.Host}
+		ch <- prometheus.MustNewConstMetric(indexUsageDesc, prometheus.CounterValue, indexStat.Accesses.Ops, ls...)
+		if !indexStat.Accesses.Since.IsZero() {
+			ch <- prometheus.MustNewConstMetric(indexUsageSinceDesc, prometheus.GaugeValue, float64(indexStat.Accesses.Since.Unix()), ls...)
+		}
+		value := 0.0
+		if indexStat.Unused {
+			value = 1
+		}
+		ch <- prometheus.MustNewConstMetric(indexUnusedDesc, prometheus.GaugeValue, value, ls...)
+
+		key := indexKey{indexStat.Collection, indexStat.Database, indexStat.Name}
+		if everywhere, seen := unused[key]; !seen || everywhere {
+			unused[key] = indexStat.Unused
 		}
-		indexUsage.With(ls).Add(indexStat.Accesses.Ops)
+		sizes[key] += indexStat.Size
+	}
+
+	reclaimable := make(map[[2]string]float64)
+	for key, everywhere := range unused {
+		if everywhere {
+			reclaimable[[2]string{key.coll, key.db}] += sizes[key]
+		}
+	}
+	for ls, val := range reclaimable {
+		ch <- prometheus.MustNewConstMetric(indexUnusedReclaimableBytesDesc, prometheus.GaugeValue, val, ls[:]...)
 	}
-	indexUsage.Collect(ch)
 }
 
 // Describe describes database stats for prometheus
 func (indexStats *IndexStatsList) Describe(ch chan<- *prometheus.Desc) {
-	indexUsage.Describe(ch)
+	ch <- indexUsageDesc
+	ch <- indexUsageSinceDesc
+	ch <- indexUnusedDesc
+	ch <- indexUnusedReclaimableBytesDesc
 }
 
 var (
 	logSuppressIS = make(map[string]bool)
 )
 
-// GetIndexUsageStatList returns stats for a given collection in a database
-func GetIndexUsageStatList(client *mongo.Client) *IndexStatsList {
+// GetIndexUsageStatList returns stats for a given collection in a database.
+// Indexes without access since a time older than unusedAge are flagged as unused.
+func GetIndexUsageStatList(client *mongo.Client, unusedAge time.Duration) *IndexStatsList {
 	indexUsageStatsList := &IndexStatsList{}
 	databaseNames, err := client.ListDatabaseNames(context.TODO(), bson.M{})
 	if err != nil {
@@ -125,9 +188,15 @@ func GetIndexUsageStatList(client *mongo.Client) *IndexStatsList {
 
 					delete(logSuppressIS, dbName+"."+coll.Name)
 					// Label index stats with corresponding db.collection
+					hasUnused := false
 					for i := 0; i < len(collIndexUsageStats.Items); i++ {
 						collIndexUsageStats.Items[i].Database = dbName
 						collIndexUsageStats.Items[i].Collection = coll.Name
+						collIndexUsageStats.Items[i].Unused = collIndexUsageStats.Items[i].isUnused(unusedAge, time.Now())
+						hasUnused = hasUnused || collIndexUsageStats.Items[i].Unused
+					}
+					if hasUnused {
+						setIndexSizes(client, dbName, coll.Name, collIndexUsageStats.Items)
 					}
 					indexUsageStatsList.Items = append(indexUsageStatsList.Items, collIndexUsageStats.Items...)
 				}
@@ -140,3 +209,29 @@ func GetIndexUsageStatList(client *mongo.Client) *IndexStatsList {
 
 	return indexUsageStatsList
 }
+
+// setIndexSizes sets the size of the unused indexes from collStats indexSizes,
+// of the shard of the index through mongos.
+func setIndexSizes(client *mongo.Client, db, coll string, items []IndexUsageStats) {
+	collStatus := struct {
+		IndexSizes map[string]float64 `bson:"indexSizes"`
+		Shards     map[string]struct {
+			IndexSizes map[string]float64 `bson:"indexSizes"`
+		} `bson:"shards"`
+	}{}
+	err := client.Database(db).RunCommand(context.TODO(), bson.D{{Key: "collStats", Value: coll}, {Key: "scale", Value: 1}}).Decode(&collStatus)
+	if err != nil {
+		log.Debugf("Could not get index sizes of %s.%s, reason: %v", db, coll, err)
+		return
+	}
+	for i := range items {
+		if !items[i].Unused {
+			continue
+		}
+		if shard, ok := collStatus.Shards[items[i].Shard]; ok {
+			items[i].Size = shard.IndexSizes[items[i].Name]
+		} else {
+			items[i].Size = collStatus.IndexSizes[items[i].Name]
+		}
+	}
+}
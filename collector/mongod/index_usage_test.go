@@ -0,0 +1,83 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/percona/mongodb_exporter/testutils"
+)
+
+func TestIndexUsageUnused(t *testing.T) {
+	now := time.Date(2019, 7, 1, 0, 0, 0, 0, time.UTC)
+	old := now.Add(-30 * 24 * time.Hour)
+	age := 7 * 24 * time.Hour
+
+	for _, tt := range []struct {
+		name   string
+		stat   IndexUsageStats
+		unused bool
+	}{
+		{"old and unused", IndexUsageStats{Name: "a_1", Accesses: IndexUsageInfo{Since: old}}, true},
+		{"old and used", IndexUsageStats{Name: "a_1", Accesses: IndexUsageInfo{Ops: 1, Since: old}}, false},
+		{"recent", IndexUsageStats{Name: "a_1", Accesses: IndexUsageInfo{Since: now.Add(-time.Hour)}}, false},
+		{"_id", IndexUsageStats{Name: "_id_", Accesses: IndexUsageInfo{Since: old}}, false},
+		{"no since", IndexUsageStats{Name: "a_1"}, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.unused, tt.stat.isUnused(age, now))
+		})
+	}
+}
+
+func TestIndexUsageExport(t *testing.T) {
+	since := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	stat := IndexUsageStats{}
+	testutils.MustDecodeBSON(t, bson.M{
+		"name":     "a_1",
+		"host":     "rs1:27017",
+		"shard":    "rs1",
+		"accesses": bson.M{"ops": int64(0), "since": since},
+	}, &stat)
+	assert.Equal(t, since, stat.Accesses.Since.UTC())
+
+	list := &IndexStatsList{Items: []IndexUsageStats{
+		{Name: "a_1", Database: "test", Collection: "coll", Host: "rs1:27017", Shard: "rs1", Accesses: stat.Accesses, Unused: true, Size: 4096},
+		{Name: "a_1", Database: "test", Collection: "coll", Host: "rs2:27017", Shard: "rs2", Accesses: IndexUsageInfo{Ops: 5, Since: since}},
+		{Name: "b_1", Database: "test", Collection: "coll", Host: "rs1:27017", Shard: "rs1", Accesses: stat.Accesses, Unused: true, Size: 1024},
+	}}
+
+	// the export must not accumulate across scrapes
+	for i := 0; i < 2; i++ {
+		values := testutils.CollectMetrics(list.Export, "index", "shard")
+		assert.Equal(t, map[string]float64{
+			"mongodb_mongod_index_usage_count/a_1/rs1":         0,
+			"mongodb_mongod_index_usage_count/a_1/rs2":         5,
+			"mongodb_mongod_index_usage_count/b_1/rs1":         0,
+			"mongodb_mongod_index_usage_since_seconds/a_1/rs1": float64(since.Unix()),
+			"mongodb_mongod_index_usage_since_seconds/a_1/rs2": float64(since.Unix()),
+			"mongodb_mongod_index_usage_since_seconds/b_1/rs1": float64(since.Unix()),
+			"mongodb_mongod_index_unused/a_1/rs1":              1,
+			"mongodb_mongod_index_unused/a_1/rs2":              0,
+			"mongodb_mongod_index_unused/b_1/rs1":              1,
+			// a_1 is used on rs2, so it can't be dropped
+			"mongodb_mongod_index_unused_reclaimable_bytes": 1024,
+		}, values)
+	}
+}
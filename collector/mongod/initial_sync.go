@@ -0,0 +1,191 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// initialSyncPhases are the phases of an initial sync, in order.
+var initialSyncPhases = []string{"initializing", "cloning", "applying_oplog", "completed"}
+
+var (
+	initialSyncPhaseDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "initial_sync_phase"),
+		"The current phase of the initial sync of this member (1) among all phases (0).",
+		[]string{"set", "phase"},
+		nil,
+	)
+	initialSyncDatabasesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "initial_sync_databases"),
+		"The number of databases cloned and to clone in total by the initial sync.",
+		[]string{"set", "type"},
+		nil,
+	)
+	initialSyncBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "initial_sync_bytes"),
+		"The bytes copied and the approximate total bytes to copy by the initial sync.",
+		[]string{"set", "type"},
+		nil,
+	)
+	initialSyncFailedAttemptsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "initial_sync_failed_attempts"),
+		"The number of failed attempts of the current initial sync.",
+		[]string{"set"},
+		nil,
+	)
+	initialSyncMaxFailedAttemptsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "initial_sync_max_failed_attempts"),
+		"The number of failed attempts after which the initial sync is abandoned.",
+		[]string{"set"},
+		nil,
+	)
+	initialSyncStartDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "initial_sync_start_timestamp"),
+		"The timestamp the current initial sync started at.",
+		[]string{"set"},
+		nil,
+	)
+	initialSyncElapsedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "initial_sync_elapsed_seconds"),
+		"The time spent on the initial sync.",
+		[]string{"set"},
+		nil,
+	)
+	initialSyncRemainingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "initial_sync_remaining_estimated_seconds"),
+		"The estimated time left to complete the initial sync.",
+		[]string{"set"},
+		nil,
+	)
+	initialSyncAppliedOpsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "initial_sync_applied_ops"),
+		"The number of oplog entries applied by the initial sync.",
+		[]string{"set"},
+		nil,
+	)
+)
+
+// InitialSyncStatus is the replSetGetStatus initialSyncStatus, returned with initialSync: 1 (3.4+).
+type InitialSyncStatus struct {
+	FailedInitialSyncAttempts     float64    `bson:"failedInitialSyncAttempts"`
+	MaxFailedInitialSyncAttempts  float64    `bson:"maxFailedInitialSyncAttempts"`
+	InitialSyncStart              *time.Time `bson:"initialSyncStart,omitempty"`
+	TotalInitialSyncElapsedMillis *float64   `bson:"totalInitialSyncElapsedMillis,omitempty"`
+	AppliedOps                    *float64   `bson:"appliedOps,omitempty"`
+	Databases                     bson.Raw   `bson:"databases,omitempty"`
+
+	// new in version 4.4
+	ApproxTotalDataSize                 *float64 `bson:"approxTotalDataSize,omitempty"`
+	ApproxTotalBytesCopied              *float64 `bson:"approxTotalBytesCopied,omitempty"`
+	RemainingInitialSyncEstimatedMillis *float64 `bson:"remainingInitialSyncEstimatedMillis,omitempty"`
+}
+
+// DatabasesProgress returns the number of databases cloned and to clone in total.
+func (status *InitialSyncStatus) DatabasesProgress() (cloned, total float64, ok bool) {
+	if len(status.Databases) == 0 {
+		return 0, 0, false
+	}
+	cloned, ok = rawValueToFloat64(status.Databases.Lookup("databasesCloned"))
+	if !ok {
+		return 0, 0, false
+	}
+
+	// databasesToClone is new in version 4.4, before the databases are listed as subdocuments
+	if toClone, ok := rawValueToFloat64(status.Databases.Lookup("databasesToClone")); ok {
+		return cloned, cloned + toClone, true
+	}
+	elements, err := status.Databases.Elements()
+	if err != nil {
+		return 0, 0, false
+	}
+	for _, element := range elements {
+		if _, isDoc := element.Value().DocumentOK(); isDoc {
+			total++
+		}
+	}
+	return cloned, total, true
+}
+
+// Phase returns the current phase of the initial sync for the given member state.
+func (status *InitialSyncStatus) Phase(myState int32) string {
+	// the status is kept after the initial sync is done up to version 4.0
+	if myState != 5 { // STARTUP2
+		return "completed"
+	}
+	cloned, total, ok := status.DatabasesProgress()
+	switch {
+	case !ok:
+		return "initializing"
+	case cloned < total:
+		return "cloning"
+	default:
+		return "applying_oplog"
+	}
+}
+
+// Export exports the initial sync status of the replica set.
+func (status *InitialSyncStatus) Export(ch chan<- prometheus.Metric, set string, myState int32) {
+	current := status.Phase(myState)
+	for _, phase := range initialSyncPhases {
+		value := 0.0
+		if phase == current {
+			value = 1
+		}
+		ch <- prometheus.MustNewConstMetric(initialSyncPhaseDesc, prometheus.GaugeValue, value, set, phase)
+	}
+
+	if cloned, total, ok := status.DatabasesProgress(); ok {
+		ch <- prometheus.MustNewConstMetric(initialSyncDatabasesDesc, prometheus.GaugeValue, cloned, set, "cloned")
+		ch <- prometheus.MustNewConstMetric(initialSyncDatabasesDesc, prometheus.GaugeValue, total, set, "total")
+	}
+	if status.ApproxTotalBytesCopied != nil {
+		ch <- prometheus.MustNewConstMetric(initialSyncBytesDesc, prometheus.GaugeValue, *status.ApproxTotalBytesCopied, set, "copied")
+	}
+	if status.ApproxTotalDataSize != nil {
+		ch <- prometheus.MustNewConstMetric(initialSyncBytesDesc, prometheus.GaugeValue, *status.ApproxTotalDataSize, set, "approx_total")
+	}
+
+	ch <- prometheus.MustNewConstMetric(initialSyncFailedAttemptsDesc, prometheus.GaugeValue, status.FailedInitialSyncAttempts, set)
+	ch <- prometheus.MustNewConstMetric(initialSyncMaxFailedAttemptsDesc, prometheus.GaugeValue, status.MaxFailedInitialSyncAttempts, set)
+	if status.InitialSyncStart != nil {
+		ch <- prometheus.MustNewConstMetric(initialSyncStartDesc, prometheus.GaugeValue, float64(status.InitialSyncStart.Unix()), set)
+	}
+	if status.TotalInitialSyncElapsedMillis != nil {
+		ch <- prometheus.MustNewConstMetric(initialSyncElapsedDesc, prometheus.GaugeValue, *status.TotalInitialSyncElapsedMillis/1000, set)
+	}
+	if status.RemainingInitialSyncEstimatedMillis != nil {
+		ch <- prometheus.MustNewConstMetric(initialSyncRemainingDesc, prometheus.GaugeValue, *status.RemainingInitialSyncEstimatedMillis/1000, set)
+	}
+	if status.AppliedOps != nil {
+		ch <- prometheus.MustNewConstMetric(initialSyncAppliedOpsDesc, prometheus.GaugeValue, *status.AppliedOps, set)
+	}
+}
+
+// Describe describes the initial sync metrics for prometheus.
+func (status *InitialSyncStatus) Describe(ch chan<- *prometheus.Desc) {
+	ch <- initialSyncPhaseDesc
+	ch <- initialSyncDatabasesDesc
+	ch <- initialSyncBytesDesc
+	ch <- initialSyncFailedAttemptsDesc
+	ch <- initialSyncMaxFailedAttemptsDesc
+	ch <- initialSyncStartDesc
+	ch <- initialSyncElapsedDesc
+	ch <- initialSyncRemainingDesc
+	ch <- initialSyncAppliedOpsDesc
+}
@@ -0,0 +1,93 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestReplSetStatusInitialSync(t *testing.T) {
+	start := time.Unix(1561982400, 0)
+
+	t.Run("4.4", func(t *testing.T) {
+		values := exportReplSetStatus(t, bson.M{
+			"set":     "rs0",
+			"myState": int32(5),
+			"initialSyncStatus": bson.M{
+				"failedInitialSyncAttempts":           int32(1),
+				"maxFailedInitialSyncAttempts":        int32(10),
+				"initialSyncStart":                    start,
+				"totalInitialSyncElapsedMillis":       int64(60000),
+				"approxTotalDataSize":                 int64(1000),
+				"approxTotalBytesCopied":              int64(250),
+				"remainingInitialSyncEstimatedMillis": int64(180000),
+				"appliedOps":                          int32(0),
+				"databases": bson.M{
+					"databasesToClone": int32(2),
+					"databasesCloned":  int32(1),
+					"admin":            bson.M{"collections": int32(2), "clonedCollections": int32(2)},
+				},
+			},
+		})
+
+		assert.Equal(t, 1.0, values["mongodb_mongod_replset_initial_sync_phase/cloning"])
+		assert.Equal(t, 0.0, values["mongodb_mongod_replset_initial_sync_phase/completed"])
+		assert.Equal(t, 1.0, values["mongodb_mongod_replset_initial_sync_databases/cloned"])
+		assert.Equal(t, 3.0, values["mongodb_mongod_replset_initial_sync_databases/total"])
+		assert.Equal(t, 250.0, values["mongodb_mongod_replset_initial_sync_bytes/copied"])
+		assert.Equal(t, 1000.0, values["mongodb_mongod_replset_initial_sync_bytes/approx_total"])
+		assert.Equal(t, 1.0, values["mongodb_mongod_replset_initial_sync_failed_attempts"])
+		assert.Equal(t, 10.0, values["mongodb_mongod_replset_initial_sync_max_failed_attempts"])
+		assert.Equal(t, float64(start.Unix()), values["mongodb_mongod_replset_initial_sync_start_timestamp"])
+		assert.Equal(t, 60.0, values["mongodb_mongod_replset_initial_sync_elapsed_seconds"])
+		assert.Equal(t, 180.0, values["mongodb_mongod_replset_initial_sync_remaining_estimated_seconds"])
+	})
+
+	t.Run("3.6", func(t *testing.T) {
+		values := exportReplSetStatus(t, bson.M{
+			"set":     "rs0",
+			"myState": int32(5),
+			"initialSyncStatus": bson.M{
+				"failedInitialSyncAttempts":    int32(0),
+				"maxFailedInitialSyncAttempts": int32(10),
+				"databases": bson.M{
+					"databasesCloned": int32(2),
+					"admin":           bson.M{"collections": int32(2)},
+					"test":            bson.M{"collections": int32(1)},
+				},
+			},
+		})
+
+		assert.Equal(t, 1.0, values["mongodb_mongod_replset_initial_sync_phase/applying_oplog"])
+		assert.Equal(t, 2.0, values["mongodb_mongod_replset_initial_sync_databases/total"])
+		_, ok := values["mongodb_mongod_replset_initial_sync_bytes/copied"]
+		assert.False(t, ok)
+	})
+
+	t.Run("completed", func(t *testing.T) {
+		values := exportReplSetStatus(t, bson.M{
+			"set":               "rs0",
+			"myState":           int32(2),
+			"initialSyncStatus": bson.M{"failedInitialSyncAttempts": int32(0)},
+		})
+
+		assert.Equal(t, 1.0, values["mongodb_mongod_replset_initial_sync_phase/completed"])
+		assert.Equal(t, 0.0, values["mongodb_mongod_replset_initial_sync_phase/initializing"])
+	})
+}
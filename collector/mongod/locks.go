@@ -42,8 +42,34 @@ var (
 		nil,
 	)
 )
+var (
+	locksAcquireTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "locks", "acquire_total"),
+		"Number of times the lock was acquired in the specified mode",
+		[]string{"resource", "mode"},
+		nil,
+	)
+	locksAcquireWaitTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "locks", "acquire_wait_total"),
+		"Number of times the lock acquisitions encountered waits because the locks were held in a conflicting mode",
+		[]string{"resource", "mode"},
+		nil,
+	)
+	locksTimeAcquiringMicrosecondsTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "locks", "time_acquiring_microseconds_total"),
+		"Cumulative wait time in microseconds for the lock acquisitions",
+		[]string{"resource", "mode"},
+		nil,
+	)
+	locksDeadlockTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "locks", "deadlock_total"),
+		"Number of times the lock acquisitions encountered deadlocks",
+		[]string{"resource", "mode"},
+		nil,
+	)
+)
 
-// LockStatsMap is a map of lock stats
+// LockStatsMap is a map of lock stats keyed by database (2.x) or by resource (3.0+)
 type LockStatsMap map[string]LockStats
 
 // ReadWriteLockTimes information about the lock
@@ -54,28 +80,58 @@ type ReadWriteLockTimes struct {
 	WriteLower float64 `bson:"w"`
 }
 
+// LockModeStats holds a lock statistic broken down by lock mode (r, w, R, W)
+type LockModeStats map[string]float64
+
 // LockStats lock stats
 type LockStats struct {
-	TimeLockedMicros    ReadWriteLockTimes `bson:"timeLockedMicros"`
-	TimeAcquiringMicros ReadWriteLockTimes `bson:"timeAcquiringMicros"`
+	// MongoDB 2.x only
+	TimeLockedMicros *ReadWriteLockTimes `bson:"timeLockedMicros,omitempty"`
+
+	// 3.0+ only, except timeAcquiringMicros which is present in both shapes
+	AcquireCount        LockModeStats `bson:"acquireCount,omitempty"`
+	AcquireWaitCount    LockModeStats `bson:"acquireWaitCount,omitempty"`
+	TimeAcquiringMicros LockModeStats `bson:"timeAcquiringMicros,omitempty"`
+	DeadlockCount       LockModeStats `bson:"deadlockCount,omitempty"`
 }
 
 // Export exports the data to prometheus.
 func (locks LockStatsMap) Export(ch chan<- prometheus.Metric) {
 	for key, locks := range locks {
-		if key == "." {
-			key = "dot"
+		if locks.TimeLockedMicros != nil {
+			locks.exportLegacy(ch, key)
+			continue
 		}
 
-		ch <- prometheus.MustNewConstMetric(locksTimeLockedGlobalMicrosecondsTotalDesc, prometheus.CounterValue, locks.TimeLockedMicros.Read, "read", key)
-		ch <- prometheus.MustNewConstMetric(locksTimeLockedGlobalMicrosecondsTotalDesc, prometheus.CounterValue, locks.TimeLockedMicros.Write, "write", key)
-
-		ch <- prometheus.MustNewConstMetric(locksTimeLockedLocalMicrosecondsTotalDesc, prometheus.CounterValue, locks.TimeLockedMicros.ReadLower, "read", key)
-		ch <- prometheus.MustNewConstMetric(locksTimeLockedLocalMicrosecondsTotalDesc, prometheus.CounterValue, locks.TimeLockedMicros.WriteLower, "write", key)
+		for mode, val := range locks.AcquireCount {
+			ch <- prometheus.MustNewConstMetric(locksAcquireTotalDesc, prometheus.CounterValue, val, key, mode)
+		}
+		for mode, val := range locks.AcquireWaitCount {
+			ch <- prometheus.MustNewConstMetric(locksAcquireWaitTotalDesc, prometheus.CounterValue, val, key, mode)
+		}
+		for mode, val := range locks.TimeAcquiringMicros {
+			ch <- prometheus.MustNewConstMetric(locksTimeAcquiringMicrosecondsTotalDesc, prometheus.CounterValue, val, key, mode)
+		}
+		for mode, val := range locks.DeadlockCount {
+			ch <- prometheus.MustNewConstMetric(locksDeadlockTotalDesc, prometheus.CounterValue, val, key, mode)
+		}
+	}
+}
 
-		ch <- prometheus.MustNewConstMetric(locksTimeAcquiringGlobalMicrosecondsTotalDesc, prometheus.CounterValue, locks.TimeAcquiringMicros.ReadLower, "read", key)
-		ch <- prometheus.MustNewConstMetric(locksTimeAcquiringGlobalMicrosecondsTotalDesc, prometheus.CounterValue, locks.TimeAcquiringMicros.WriteLower, "write", key)
+// exportLegacy exports the MongoDB 2.x per-database lock times.
+func (locks LockStats) exportLegacy(ch chan<- prometheus.Metric, key string) {
+	if key == "." {
+		key = "dot"
 	}
+
+	ch <- prometheus.MustNewConstMetric(locksTimeLockedGlobalMicrosecondsTotalDesc, prometheus.CounterValue, locks.TimeLockedMicros.Read, "read", key)
+	ch <- prometheus.MustNewConstMetric(locksTimeLockedGlobalMicrosecondsTotalDesc, prometheus.CounterValue, locks.TimeLockedMicros.Write, "write", key)
+
+	ch <- prometheus.MustNewConstMetric(locksTimeLockedLocalMicrosecondsTotalDesc, prometheus.CounterValue, locks.TimeLockedMicros.ReadLower, "read", key)
+	ch <- prometheus.MustNewConstMetric(locksTimeLockedLocalMicrosecondsTotalDesc, prometheus.CounterValue, locks.TimeLockedMicros.WriteLower, "write", key)
+
+	ch <- prometheus.MustNewConstMetric(locksTimeAcquiringGlobalMicrosecondsTotalDesc, prometheus.CounterValue, locks.TimeAcquiringMicros["r"], "read", key)
+	ch <- prometheus.MustNewConstMetric(locksTimeAcquiringGlobalMicrosecondsTotalDesc, prometheus.CounterValue, locks.TimeAcquiringMicros["w"], "write", key)
 }
 
 // Describe describes the metrics for prometheus
@@ -83,4 +139,8 @@ func (locks LockStatsMap) Describe(ch chan<- *prometheus.Desc) {
 	ch <- locksTimeLockedGlobalMicrosecondsTotalDesc
 	ch <- locksTimeLockedLocalMicrosecondsTotalDesc
 	ch <- locksTimeAcquiringGlobalMicrosecondsTotalDesc
+	ch <- locksAcquireTotalDesc
+	ch <- locksAcquireWaitTotalDesc
+	ch <- locksTimeAcquiringMicrosecondsTotalDesc
+	ch <- locksDeadlockTotalDesc
 }
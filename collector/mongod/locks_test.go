@@ -0,0 +1,75 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/percona/mongodb_exporter/testutils"
+)
+
+func collectLockMetrics(t *testing.T, doc bson.M, labels ...string) map[string]float64 {
+	status := &ServerStatus{}
+	testutils.MustDecodeBSON(t, bson.M{"locks": doc}, status)
+	return testutils.CollectMetrics(status.Locks.Export, labels...)
+}
+
+func TestLockStatsModern(t *testing.T) {
+	values := collectLockMetrics(t, bson.M{
+		"Global": bson.M{
+			"acquireCount":        bson.M{"r": int64(1500), "w": int64(20), "W": int32(4)},
+			"acquireWaitCount":    bson.M{"w": int64(2)},
+			"timeAcquiringMicros": bson.M{"w": int64(350)},
+		},
+		"Collection": bson.M{
+			"acquireCount":  bson.M{"r": int64(900)},
+			"deadlockCount": bson.M{"r": int64(1)},
+		},
+	}, "resource", "mode")
+
+	assert.Equal(t, map[string]float64{
+		"mongodb_mongod_locks_acquire_total/Global/r":                     1500,
+		"mongodb_mongod_locks_acquire_total/Global/w":                     20,
+		"mongodb_mongod_locks_acquire_total/Global/W":                     4,
+		"mongodb_mongod_locks_acquire_wait_total/Global/w":                2,
+		"mongodb_mongod_locks_time_acquiring_microseconds_total/Global/w": 350,
+		"mongodb_mongod_locks_acquire_total/Collection/r":                 900,
+		"mongodb_mongod_locks_deadlock_total/Collection/r":                1,
+	}, values)
+}
+
+func TestLockStatsLegacy(t *testing.T) {
+	values := collectLockMetrics(t, bson.M{
+		".": bson.M{
+			"timeLockedMicros":    bson.M{"R": int64(10), "W": int64(20)},
+			"timeAcquiringMicros": bson.M{"R": int64(30), "W": int64(40)},
+		},
+		"admin": bson.M{
+			"timeLockedMicros":    bson.M{"r": int64(50), "w": int64(60)},
+			"timeAcquiringMicros": bson.M{"r": int64(70), "w": int64(80)},
+		},
+	}, "database", "type")
+
+	for key := range values {
+		assert.NotContains(t, key, "mongodb_mongod_locks_acquire")
+	}
+	assert.Equal(t, 10.0, values["mongodb_mongod_locks_time_locked_global_microseconds_total/dot/read"])
+	assert.Equal(t, 60.0, values["mongodb_mongod_locks_time_locked_local_microseconds_total/admin/write"])
+	assert.Equal(t, 70.0, values["mongodb_mongod_locks_time_acquiring_global_microseconds_total/admin/read"])
+	assert.Len(t, values, 12)
+}
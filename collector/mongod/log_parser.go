@@ -0,0 +1,182 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Log events recognized in both the legacy and the structured log formats.
+const (
+	LogEventSlowOperation      = "slow_operation"
+	LogEventConnectionAccepted = "connection_accepted"
+	LogEventConnectionEnded    = "connection_ended"
+	LogEventAssertion          = "assertion"
+	LogEventElection           = "election"
+)
+
+var (
+	// 2019-06-20T10:24:13.469+0000 I NETWORK  [listener] connection accepted from 127.0.0.1:53110 #1 (1 connection now open)
+	legacyLogLineRegexp = regexp.MustCompile(`^(\S+)\s+([FEWID]\d?)\s+(\S+)\s+\[([^\]]*)\]\s?(.*)$`)
+	// command test.foo command: find { find: "foo" } planSummary: COLLSCAN ... protocol:op_msg 150ms
+	legacySlowOpRegexp = regexp.MustCompile(`^(command|query|update|remove|insert|getmore|killcursors|write)\s+(\S+)\s.*\s(\d+)ms$`)
+)
+
+// LogEntry is a single mongod log line decoded from either log format.
+type LogEntry struct {
+	Time      time.Time
+	Severity  string
+	Component string
+	ID        string
+	Context   string
+	Message   string
+
+	// Event is one of the LogEvent* constants, or empty if the line is not a recognized event.
+	Event string
+	// Namespace and Duration are only set for slow operations.
+	Namespace string
+	Duration  time.Duration
+}
+
+// structuredLogLine is the 4.4+ JSON log line layout.
+// See: https://docs.mongodb.com/manual/reference/log-messages/#structured-logging
+type structuredLogLine struct {
+	T struct {
+		Date string `json:"$date"`
+	} `json:"t"`
+	S    string                 `json:"s"`
+	C    string                 `json:"c"`
+	ID   json.Number            `json:"id"`
+	Ctx  string                 `json:"ctx"`
+	Msg  string                 `json:"msg"`
+	Attr map[string]interface{} `json:"attr"`
+}
+
+// ParseLogLine parses a mongod log line in the legacy text format or the 4.4+ structured JSON format.
+func ParseLogLine(line string) (*LogEntry, error) {
+	line = strings.TrimRight(line, "\r\n")
+	if strings.HasPrefix(line, "{") {
+		return parseStructuredLogLine(line)
+	}
+	return parseLegacyLogLine(line)
+}
+
+func parseStructuredLogLine(line string) (*LogEntry, error) {
+	var l structuredLogLine
+	if err := json.Unmarshal([]byte(line), &l); err != nil {
+		return nil, fmt.Errorf("cannot decode structured log line: %s", err)
+	}
+	if l.S == "" || l.C == "" {
+		return nil, fmt.Errorf("structured log line has no severity or component")
+	}
+
+	entry := &LogEntry{
+		Severity:  normalizeLogSeverity(l.S),
+		Component: l.C,
+		ID:        l.ID.String(),
+		Context:   l.Ctx,
+		Message:   l.Msg,
+	}
+	if t, err := time.Parse("2006-01-02T15:04:05.000Z07:00", l.T.Date); err == nil {
+		entry.Time = t
+	}
+
+	switch {
+	case l.Msg == "Slow query":
+		entry.Event = LogEventSlowOperation
+		if ns, ok := l.Attr["ns"].(string); ok {
+			entry.Namespace = ns
+		}
+		if ms, ok := l.Attr["durationMillis"].(float64); ok {
+			entry.Duration = time.Duration(ms * float64(time.Millisecond))
+		}
+	case l.Msg == "Connection accepted":
+		entry.Event = LogEventConnectionAccepted
+	case l.Msg == "Connection ended":
+		entry.Event = LogEventConnectionEnded
+	case l.C == "ASSERT" || strings.Contains(l.Msg, "Assertion"):
+		entry.Event = LogEventAssertion
+	case l.C == "ELECTION":
+		entry.Event = LogEventElection
+	}
+
+	return entry, nil
+}
+
+func parseLegacyLogLine(line string) (*LogEntry, error) {
+	m := legacyLogLineRegexp.FindStringSubmatch(line)
+	if m == nil {
+		return nil, fmt.Errorf("unrecognized log line format")
+	}
+
+	entry := &LogEntry{
+		Severity:  normalizeLogSeverity(m[2]),
+		Component: m[3],
+		Context:   m[4],
+		Message:   m[5],
+	}
+	if entry.Component == "-" {
+		entry.Component = ""
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05.000-0700", "2006-01-02T15:04:05.000Z07:00"} {
+		if t, err := time.Parse(layout, m[1]); err == nil {
+			entry.Time = t
+			break
+		}
+	}
+
+	msg := entry.Message
+	switch {
+	case strings.HasPrefix(msg, "connection accepted from"):
+		entry.Event = LogEventConnectionAccepted
+	case strings.HasPrefix(msg, "end connection"):
+		entry.Event = LogEventConnectionEnded
+	case entry.Component == "ASSERT" || strings.Contains(msg, "Assertion"):
+		entry.Event = LogEventAssertion
+	case entry.Component == "ELECTION" || (entry.Component == "REPL" && strings.Contains(strings.ToLower(msg), "election")):
+		entry.Event = LogEventElection
+	default:
+		if sm := legacySlowOpRegexp.FindStringSubmatch(msg); sm != nil {
+			ms, _ := strconv.ParseFloat(sm[3], 64)
+			entry.Event = LogEventSlowOperation
+			entry.Namespace = sm[2]
+			entry.Duration = time.Duration(ms * float64(time.Millisecond))
+		}
+	}
+
+	return entry, nil
+}
+
+// normalizeLogSeverity maps legacy and structured severity codes to a common name.
+func normalizeLogSeverity(s string) string {
+	switch {
+	case s == "F":
+		return "fatal"
+	case s == "E":
+		return "error"
+	case s == "W":
+		return "warning"
+	case s == "I":
+		return "info"
+	case strings.HasPrefix(s, "D"):
+		return "debug"
+	}
+	return strings.ToLower(s)
+}
@@ -0,0 +1,147 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLogLine(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		severity  string
+		component string
+		id        string
+		event     string
+		ns        string
+		duration  time.Duration
+	}{
+		{
+			name:      "legacy connection accepted",
+			line:      "2019-06-20T10:24:13.469+0000 I NETWORK  [listener] connection accepted from 127.0.0.1:53110 #1 (1 connection now open)",
+			severity:  "info",
+			component: "NETWORK",
+			event:     LogEventConnectionAccepted,
+		},
+		{
+			name:      "legacy end connection",
+			line:      "2019-06-20T10:24:15.001+0000 I NETWORK  [conn1] end connection 127.0.0.1:53110 (0 connections now open)",
+			severity:  "info",
+			component: "NETWORK",
+			event:     LogEventConnectionEnded,
+		},
+		{
+			name:      "legacy slow command",
+			line:      `2019-06-20T10:25:01.123+0000 I COMMAND  [conn5] command test.foo command: find { find: "foo", filter: { a: 1 } } planSummary: COLLSCAN keysExamined:0 docsExamined:100000 numYields:781 nreturned:1 reslen:228 protocol:op_msg 150ms`,
+			severity:  "info",
+			component: "COMMAND",
+			event:     LogEventSlowOperation,
+			ns:        "test.foo",
+			duration:  150 * time.Millisecond,
+		},
+		{
+			name:      "legacy election",
+			line:      "2019-06-20T10:26:00.000+0000 I ELECTION [replexec-3] Starting an election, since we've seen no PRIMARY in the past 10000ms",
+			severity:  "info",
+			component: "ELECTION",
+			event:     LogEventElection,
+		},
+		{
+			name:      "legacy assertion",
+			line:      "2019-06-20T10:27:00.000+0000 E QUERY    [conn7] Assertion: 13111:field not found, expected type 2",
+			severity:  "error",
+			component: "QUERY",
+			event:     LogEventAssertion,
+		},
+		{
+			name:      "legacy debug without component",
+			line:      "2019-06-20T10:28:00.000+0000 D1 -        [conn8] some debug message",
+			severity:  "debug",
+			component: "",
+		},
+		{
+			name:      "structured connection accepted",
+			line:      `{"t":{"$date":"2020-05-01T15:16:17.180+00:00"},"s":"I","c":"NETWORK","id":22943,"ctx":"listener","msg":"Connection accepted","attr":{"remote":"127.0.0.1:53110","connectionId":1,"connectionCount":1}}`,
+			severity:  "info",
+			component: "NETWORK",
+			id:        "22943",
+			event:     LogEventConnectionAccepted,
+		},
+		{
+			name:      "structured connection ended",
+			line:      `{"t":{"$date":"2020-05-01T15:16:18.180+00:00"},"s":"I","c":"NETWORK","id":22944,"ctx":"conn1","msg":"Connection ended","attr":{"remote":"127.0.0.1:53110","connectionId":1,"connectionCount":0}}`,
+			severity:  "info",
+			component: "NETWORK",
+			id:        "22944",
+			event:     LogEventConnectionEnded,
+		},
+		{
+			name:      "structured slow query",
+			line:      `{"t":{"$date":"2020-05-01T15:16:19.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn5","msg":"Slow query","attr":{"type":"command","ns":"test.foo","command":{"find":"foo"},"planSummary":"COLLSCAN","durationMillis":1234}}`,
+			severity:  "info",
+			component: "COMMAND",
+			id:        "51803",
+			event:     LogEventSlowOperation,
+			ns:        "test.foo",
+			duration:  1234 * time.Millisecond,
+		},
+		{
+			name:      "structured election",
+			line:      `{"t":{"$date":"2020-05-01T15:16:20.000+00:00"},"s":"I","c":"ELECTION","id":21450,"ctx":"ReplCoord-1","msg":"Election succeeded, assuming primary role","attr":{"term":3}}`,
+			severity:  "info",
+			component: "ELECTION",
+			id:        "21450",
+			event:     LogEventElection,
+		},
+		{
+			name:      "structured warning",
+			line:      `{"t":{"$date":"2020-05-01T15:16:21.000+00:00"},"s":"W","c":"CONTROL","id":22120,"ctx":"initandlisten","msg":"Access control is not enabled for the database"}`,
+			severity:  "warning",
+			component: "CONTROL",
+			id:        "22120",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := ParseLogLine(tt.line)
+			require.NoError(t, err)
+			assert.Equal(t, tt.severity, entry.Severity)
+			assert.Equal(t, tt.component, entry.Component)
+			assert.Equal(t, tt.id, entry.ID)
+			assert.Equal(t, tt.event, entry.Event)
+			assert.Equal(t, tt.ns, entry.Namespace)
+			assert.Equal(t, tt.duration, entry.Duration)
+			assert.False(t, entry.Time.IsZero(), "timestamp was not parsed")
+		})
+	}
+}
+
+func TestParseLogLineInvalid(t *testing.T) {
+	for _, line := range []string{
+		"not a log line",
+		`{"broken json"`,
+		`{"msg":"no severity"}`,
+	} {
+		_, err := ParseLogLine(line)
+		assert.Error(t, err, line)
+	}
+}
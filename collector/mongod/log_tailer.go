@@ -0,0 +1,234 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/log"
+)
+
+var (
+	logMessagesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "log",
+		Name:      "messages_total",
+		Help:      "The total number of mongod log messages by component, severity and message id (empty for the legacy log format)",
+	}, []string{"component", "severity", "id"})
+	logEventsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "log",
+		Name:      "events_total",
+		Help:      "The total number of recognized mongod log events (slow operations, connections accepted/ended, assertions, elections)",
+	}, []string{"event"})
+	logSlowOperationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "log",
+		Name:      "slow_operation_duration_seconds",
+		Help:      "The duration of slow operations reported in the mongod log by namespace",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 10),
+	}, []string{"ns"})
+	logParseErrorsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "log",
+		Name:      "parse_errors_total",
+		Help:      "The total number of mongod log lines that could not be parsed",
+	})
+	logReopensTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "log",
+		Name:      "reopens_total",
+		Help:      "The total number of times the mongod log file was reopened after rotation or truncation",
+	})
+)
+
+// logTailerPollInterval is how often the tailer checks the log file for new data and rotation.
+var logTailerPollInterval = time.Second
+
+// LogTailer follows a mongod log file across rotation and turns its lines into metrics.
+type LogTailer struct {
+	path string
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+	once   sync.Once
+}
+
+// NewLogTailer returns a LogTailer for the mongod log file at path.
+// Call Start to begin following the file.
+func NewLogTailer(path string) *LogTailer {
+	return &LogTailer{
+		path:   path,
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+}
+
+// Start begins following the log file in the background from its current end.
+func (t *LogTailer) Start() {
+	go t.run()
+}
+
+// Stop stops following the log file and waits for the background goroutine to finish.
+func (t *LogTailer) Stop() {
+	t.once.Do(func() {
+		close(t.stopCh)
+		<-t.doneCh
+	})
+}
+
+func (t *LogTailer) run() {
+	defer close(t.doneCh)
+
+	var (
+		f       *os.File
+		info    os.FileInfo
+		reader  *bufio.Reader
+		offset  int64
+		partial string
+	)
+	defer func() {
+		if f != nil {
+			f.Close()
+		}
+	}()
+
+	// open (re)opens the log file. The first open starts at the end of the file so that
+	// history is not replayed; after rotation the new file is read from the beginning.
+	open := func(fromStart bool) bool {
+		if f != nil {
+			f.Close()
+			f = nil
+		}
+		nf, err := os.Open(t.path)
+		if err != nil {
+			return false
+		}
+		fi, err := nf.Stat()
+		if err != nil {
+			nf.Close()
+			return false
+		}
+		offset = 0
+		if !fromStart {
+			if offset, err = nf.Seek(0, io.SeekEnd); err != nil {
+				nf.Close()
+				return false
+			}
+		}
+		f, info, reader, partial = nf, fi, bufio.NewReader(nf), ""
+		return true
+	}
+
+	if !open(false) {
+		log.Errorf("Cannot open mongod log file %s, will keep retrying.", t.path)
+	}
+
+	ticker := time.NewTicker(logTailerPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if f != nil {
+			for {
+				line, err := reader.ReadString('\n')
+				offset += int64(len(line))
+				if err != nil {
+					partial += line
+					break
+				}
+				t.process(partial + line)
+				partial = ""
+			}
+		}
+
+		select {
+		case <-t.stopCh:
+			return
+		case <-ticker.C:
+		}
+
+		fi, err := os.Stat(t.path)
+		switch {
+		case err != nil:
+			// the file is being rotated; keep reading the old one until the new one shows up
+			continue
+		case f == nil:
+			if open(true) {
+				logReopensTotal.Inc()
+			}
+		case !os.SameFile(info, fi) || fi.Size() < offset:
+			// drain what is left of the old file before switching to the new one
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					if line = partial + line; line != "" {
+						t.process(line)
+					}
+					break
+				}
+				t.process(partial + line)
+				partial = ""
+			}
+			if open(true) {
+				logReopensTotal.Inc()
+			}
+		}
+	}
+}
+
+func (t *LogTailer) process(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	entry, err := ParseLogLine(line)
+	if err != nil {
+		logParseErrorsTotal.Inc()
+		log.Debugf("Cannot parse mongod log line %q: %s", line, err)
+		return
+	}
+
+	logMessagesTotal.WithLabelValues(entry.Component, entry.Severity, entry.ID).Inc()
+	if entry.Event == "" {
+		return
+	}
+	logEventsTotal.WithLabelValues(entry.Event).Inc()
+	if entry.Event == LogEventSlowOperation && entry.Namespace != "" {
+		logSlowOperationSeconds.WithLabelValues(entry.Namespace).Observe(entry.Duration.Seconds())
+	}
+}
+
+// Export exports the log metrics to be consumed by prometheus.
+func (t *LogTailer) Export(ch chan<- prometheus.Metric) {
+	logMessagesTotal.Collect(ch)
+	logEventsTotal.Collect(ch)
+	logSlowOperationSeconds.Collect(ch)
+	logParseErrorsTotal.Collect(ch)
+	logReopensTotal.Collect(ch)
+}
+
+// Describe describes the log metrics for prometheus.
+func (t *LogTailer) Describe(ch chan<- *prometheus.Desc) {
+	logMessagesTotal.Describe(ch)
+	logEventsTotal.Describe(ch)
+	logSlowOperationSeconds.Describe(ch)
+	logParseErrorsTotal.Describe(ch)
+	logReopensTotal.Describe(ch)
+}
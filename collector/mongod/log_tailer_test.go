@@ -0,0 +1,85 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogTailerFollowsRotation(t *testing.T) {
+	defer func(d time.Duration) { logTailerPollInterval = d }(logTailerPollInterval)
+	logTailerPollInterval = 10 * time.Millisecond
+
+	dir, err := ioutil.TempDir("", "mongodb_exporter-logtail-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "mongod.log")
+	accepted := `{"t":{"$date":"2020-05-01T15:16:17.180+00:00"},"s":"I","c":"NETWORK","id":22943,"ctx":"listener","msg":"Connection accepted"}` + "\n"
+	ended := "2019-06-20T10:24:15.001+0000 I NETWORK  [conn1] end connection 127.0.0.1:53110 (0 connections now open)\n"
+
+	// history written before the tailer starts must not be counted
+	require.NoError(t, ioutil.WriteFile(path, []byte(accepted), 0600))
+
+	acceptedBefore := testutil.ToFloat64(logEventsTotal.WithLabelValues(LogEventConnectionAccepted))
+	endedBefore := testutil.ToFloat64(logEventsTotal.WithLabelValues(LogEventConnectionEnded))
+	reopensBefore := testutil.ToFloat64(logReopensTotal)
+
+	tailer := NewLogTailer(path)
+	tailer.Start()
+	defer tailer.Stop()
+	time.Sleep(5 * logTailerPollInterval)
+
+	appendLine(t, path, accepted)
+	waitFor(t, func() bool {
+		return testutil.ToFloat64(logEventsTotal.WithLabelValues(LogEventConnectionAccepted)) == acceptedBefore+1
+	})
+
+	// rename-style rotation: the old file moves away and a new one is created
+	require.NoError(t, os.Rename(path, path+".1"))
+	appendLine(t, path, ended)
+	waitFor(t, func() bool {
+		return testutil.ToFloat64(logEventsTotal.WithLabelValues(LogEventConnectionEnded)) == endedBefore+1
+	})
+	assert.Equal(t, reopensBefore+1, testutil.ToFloat64(logReopensTotal))
+	assert.Equal(t, acceptedBefore+1, testutil.ToFloat64(logEventsTotal.WithLabelValues(LogEventConnectionAccepted)))
+}
+
+func appendLine(t *testing.T, path, line string) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString(line)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("condition was not met in time")
+}
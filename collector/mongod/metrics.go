@@ -16,6 +16,7 @@ package mongod
 
 import (
 	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 var (
@@ -211,6 +212,14 @@ var (
 		Help:      "insertBytes the total size of documents inserted into the oplog.",
 	})
 )
+var (
+	metricsReplSyncSourceTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "metrics_repl_sync_source", "total"),
+		"syncSource reports the sync source selections of this member: the number of selections, and how many chose the same source, a different source or could not find one",
+		[]string{"type"},
+		nil,
+	)
+)
 var (
 	metricsReplPreloadDocsNumTotalDesc = prometheus.NewDesc(
 		prometheus.BuildFQName(Namespace, "metrics_repl_preload_docs", "num_total"),
@@ -261,6 +270,20 @@ var (
 		nil,
 	)
 )
+var (
+	metricsCommandsTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "metrics_commands", "total"),
+		"total reports the number of times the command has been executed",
+		[]string{"command"},
+		nil,
+	)
+	metricsCommandsFailedTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "metrics_commands", "failed_total"),
+		"failed reports the number of times the command has failed",
+		[]string{"command"},
+		nil,
+	)
+)
 
 // DocumentStats are the stats associated to a document.
 type DocumentStats struct {
@@ -399,6 +422,22 @@ func (metricsNetworkStats *MetricsNetworkStats) Export(ch chan<- prometheus.Metr
 	ch <- prometheus.MustNewConstMetric(metricsReplNetworkGetmoresTotalMillisecondsDesc, prometheus.CounterValue, metricsNetworkStats.GetMores.TotalMillis)
 }
 
+// SyncSourceStats are the stats associated with the sync source selection.
+type SyncSourceStats struct {
+	NumSelections          float64 `bson:"numSelections"`
+	NumTimesChoseSame      float64 `bson:"numTimesChoseSame"`
+	NumTimesChoseDifferent float64 `bson:"numTimesChoseDifferent"`
+	NumTimesCouldNotFind   float64 `bson:"numTimesCouldNotFind"`
+}
+
+// Export exports the sync source stats.
+func (syncSourceStats *SyncSourceStats) Export(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(metricsReplSyncSourceTotalDesc, prometheus.CounterValue, syncSourceStats.NumSelections, "selections")
+	ch <- prometheus.MustNewConstMetric(metricsReplSyncSourceTotalDesc, prometheus.CounterValue, syncSourceStats.NumTimesChoseSame, "chose_same")
+	ch <- prometheus.MustNewConstMetric(metricsReplSyncSourceTotalDesc, prometheus.CounterValue, syncSourceStats.NumTimesChoseDifferent, "chose_different")
+	ch <- prometheus.MustNewConstMetric(metricsReplSyncSourceTotalDesc, prometheus.CounterValue, syncSourceStats.NumTimesCouldNotFind, "could_not_find")
+}
+
 // ReplStats are the stats associated with the replication process.
 type ReplStats struct {
 	Apply        *ApplyStats          `bson:"apply"`
@@ -406,6 +445,7 @@ type ReplStats struct {
 	Executor     *ReplExecutorStats   `bson:"executor,omitempty"`
 	Network      *MetricsNetworkStats `bson:"network"`
 	PreloadStats *PreloadStats        `bson:"preload"`
+	SyncSource   *SyncSourceStats     `bson:"syncSource,omitempty"`
 }
 
 // Export exposes the replication stats.
@@ -426,6 +466,10 @@ func (replStats *ReplStats) Export(ch chan<- prometheus.Metric) {
 	if replStats.Executor != nil {
 		replStats.Executor.Export(ch)
 	}
+	// 4.4+ only
+	if replStats.SyncSource != nil {
+		replStats.SyncSource.Export(ch)
+	}
 }
 
 // PreloadStats are the stats associated with preload operation.
@@ -495,6 +539,60 @@ func (ttlStats *TTLStats) Export(ch chan<- prometheus.Metric) {
 	ch <- prometheus.MustNewConstMetric(metricsTTLPassesTotalDesc, prometheus.CounterValue, ttlStats.Passes)
 }
 
+// CommandStats are the counters of a single command.
+type CommandStats struct {
+	Failed float64 `bson:"failed"`
+	Total  float64 `bson:"total"`
+}
+
+// CommandsStats are the per-command counters keyed by command name.
+type CommandsStats map[string]CommandStats
+
+// UnmarshalBSON decodes metrics.commands skipping the entries that are not command counters (e.g. <UNKNOWN>).
+func (commandsStats *CommandsStats) UnmarshalBSON(data []byte) error {
+	elements, err := bson.Raw(data).Elements()
+	if err != nil {
+		return err
+	}
+	*commandsStats = make(CommandsStats, len(elements))
+	for _, element := range elements {
+		doc, ok := element.Value().DocumentOK()
+		if !ok {
+			continue
+		}
+		stats := CommandStats{}
+		if err := bson.Unmarshal(doc, &stats); err != nil {
+			continue
+		}
+		(*commandsStats)[element.Key()] = stats
+	}
+	return nil
+}
+
+// Filter removes the commands that are not in allowlist. An empty allowlist keeps all commands.
+func (commandsStats CommandsStats) Filter(allowlist []string) {
+	if len(allowlist) == 0 {
+		return
+	}
+	allowed := make(map[string]bool, len(allowlist))
+	for _, command := range allowlist {
+		allowed[command] = true
+	}
+	for command := range commandsStats {
+		if !allowed[command] {
+			delete(commandsStats, command)
+		}
+	}
+}
+
+// Export exports the commands stats.
+func (commandsStats CommandsStats) Export(ch chan<- prometheus.Metric) {
+	for command, stats := range commandsStats {
+		ch <- prometheus.MustNewConstMetric(metricsCommandsTotalDesc, prometheus.CounterValue, stats.Total, command)
+		ch <- prometheus.MustNewConstMetric(metricsCommandsFailedTotalDesc, prometheus.CounterValue, stats.Failed, command)
+	}
+}
+
 // MetricsStats are all stats associated with metrics of the system
 type MetricsStats struct {
 	Document      *DocumentStats      `bson:"document"`
@@ -506,6 +604,7 @@ type MetricsStats struct {
 	Storage       *StorageStats       `bson:"storage"`
 	Cursor        *CursorStats        `bson:"cursor"`
 	TTL           *TTLStats           `bson:"ttl"`
+	Commands      CommandsStats       `bson:"commands"`
 }
 
 // Export exports the metrics stats.
@@ -537,6 +636,9 @@ func (metricsStats *MetricsStats) Export(ch chan<- prometheus.Metric) {
 	if metricsStats.TTL != nil {
 		metricsStats.TTL.Export(ch)
 	}
+	if metricsStats.Commands != nil {
+		metricsStats.Commands.Export(ch)
+	}
 
 	metricsCursorOpen.Collect(ch)
 	metricsGetLastErrorWtimeNumTotal.Collect(ch)
@@ -580,6 +682,7 @@ func (metricsStats *MetricsStats) Describe(ch chan<- *prometheus.Desc) {
 	ch <- metricsReplNetworkBytesTotalDesc
 	ch <- metricsReplNetworkOpsTotalDesc
 	ch <- metricsReplNetworkReadersCreatedTotalDesc
+	ch <- metricsReplSyncSourceTotalDesc
 	ch <- metricsReplPreloadDocsNumTotalDesc
 	ch <- metricsReplPreloadDocsTotalMillisecondsDesc
 	ch <- metricsReplPreloadIndexesNumTotalDesc
@@ -587,4 +690,6 @@ func (metricsStats *MetricsStats) Describe(ch chan<- *prometheus.Desc) {
 	ch <- metricsStorageFreelistSearchTotalDesc
 	ch <- metricsTTLDeletedDocumentsTotalDesc
 	ch <- metricsTTLPassesTotalDesc
+	ch <- metricsCommandsTotalDesc
+	ch <- metricsCommandsFailedTotalDesc
 }
@@ -5,6 +5,10 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/percona/mongodb_exporter/testutils"
 )
 
 func TestReplStatsExportShouldNotPanic(t *testing.T) {
@@ -22,3 +26,43 @@ func TestPreloadStatsExportShouldNotPanic(t *testing.T) {
 
 	assert.NotPanics(t, f, "nil pointer in PreloadStats")
 }
+
+func TestCommandsStatsDecodeAndFilter(t *testing.T) {
+	status := &ServerStatus{}
+	testutils.MustDecodeBSON(t, bson.M{"metrics": bson.M{"commands": bson.M{
+		"<UNKNOWN>": int64(2),
+		"find":      bson.M{"failed": int64(1), "total": int64(10)},
+		"insert":    bson.M{"failed": int64(0), "total": int64(7)},
+		"update":    bson.M{"arrayFilters": int64(0), "failed": int64(3), "pipeline": int64(0), "total": int64(4)},
+	}}}, status)
+
+	assert.Equal(t, CommandsStats{
+		"find":   {Failed: 1, Total: 10},
+		"insert": {Failed: 0, Total: 7},
+		"update": {Failed: 3, Total: 4},
+	}, status.Metrics.Commands)
+
+	status.FilterCommands([]string{"find", "update", "count"})
+	assert.Equal(t, CommandsStats{
+		"find":   {Failed: 1, Total: 10},
+		"update": {Failed: 3, Total: 4},
+	}, status.Metrics.Commands)
+}
+
+func TestSyncSourceStatsExport(t *testing.T) {
+	status := &ServerStatus{}
+	testutils.MustDecodeBSON(t, bson.M{"metrics": bson.M{"repl": bson.M{"syncSource": bson.M{
+		"numSelections":          int64(5),
+		"numTimesChoseSame":      int64(2),
+		"numTimesChoseDifferent": int64(3),
+		"numTimesCouldNotFind":   int64(1),
+	}}}}, status)
+	require.NotNil(t, status.Metrics.Repl.SyncSource)
+
+	assert.Equal(t, map[string]float64{
+		"mongodb_mongod_metrics_repl_sync_source_total/selections":      5,
+		"mongodb_mongod_metrics_repl_sync_source_total/chose_same":      2,
+		"mongodb_mongod_metrics_repl_sync_source_total/chose_different": 3,
+		"mongodb_mongod_metrics_repl_sync_source_total/could_not_find":  1,
+	}, testutils.CollectMetrics(status.Metrics.Repl.SyncSource.Export, "type"))
+}
@@ -16,6 +16,8 @@ package mongod
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/log"
@@ -54,12 +56,47 @@ var (
 		Name:      "size_bytes",
 		Help:      "Size of oplog in bytes",
 	}, []string{"type"})
+	oplogStatusWindowSeconds = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "replset_oplog",
+		Name:      "window_seconds",
+		Help:      "The time range in seconds between the oldest and the newest change in the oplog",
+	})
+	oplogStatusFillRatio = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "replset_oplog",
+		Name:      "fill_ratio",
+		Help:      "The ratio between the size and the configured maximum size of the oplog",
+	})
+	oplogChurnEntriesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "replset_oplog", "churn_entries"),
+		"The number of oplog entries written during the churn window by operation and namespace",
+		[]string{"op", "ns"},
+		nil,
+	)
+	oplogChurnBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "replset_oplog", "churn_bytes"),
+		"The size in bytes of the oplog entries written during the churn window by operation and namespace (MongoDB 4.4+)",
+		[]string{"op", "ns"},
+		nil,
+	)
 )
 
 type OplogCollectionStats struct {
 	Count       float64 `bson:"count"`
 	Size        float64 `bson:"size"`
 	StorageSize float64 `bson:"storageSize"`
+	MaxSize     float64 `bson:"maxSize"`
+}
+
+// OplogChurnStats are the entries written to the oplog for an operation and namespace during the churn window
+type OplogChurnStats struct {
+	ID struct {
+		Op string `bson:"op"`
+		Ns string `bson:"ns"`
+	} `bson:"_id"`
+	Count float64  `bson:"count"`
+	Bytes *float64 `bson:"bytes"`
 }
 
 type OplogTimestamps struct {
@@ -70,6 +107,7 @@ type OplogTimestamps struct {
 type OplogStatus struct {
 	OplogTimestamps *OplogTimestamps
 	CollectionStats *OplogCollectionStats
+	Churn           []OplogChurnStats
 }
 
 func getOplogTailOrHeadTimestamp(client *mongo.Client, returnHead bool) (float64, error) {
@@ -111,23 +149,128 @@ func GetOplogCollectionStats(client *mongo.Client) (*OplogCollectionStats, error
 	return results, err
 }
 
+// invalidPipelineOperatorCode is the error code of an aggregation using an unknown operator.
+const invalidPipelineOperatorCode = 168
+
+// isInvalidPipelineOperator returns true if err reports an aggregation operator unknown to the server.
+func isInvalidPipelineOperator(err error) bool {
+	cmdErr, ok := err.(mongo.CommandError)
+	return ok && (cmdErr.Code == invalidPipelineOperatorCode || cmdErr.Name == "InvalidPipelineOperator")
+}
+
+// GetOplogChurn aggregates the oplog entries written during the last window by operation and namespace.
+func GetOplogChurn(client *mongo.Client, window time.Duration) ([]OplogChurnStats, error) {
+	since := primitive.Timestamp{T: uint32(time.Now().Add(-window).Unix())}
+	group := bson.M{
+		"_id":   bson.M{"op": "$op", "ns": "$ns"},
+		"count": bson.M{"$sum": 1},
+		"bytes": bson.M{"$sum": bson.M{"$bsonSize": "$$ROOT"}},
+	}
+	churn, err := aggregateOplogChurn(client, since, group)
+	if isInvalidPipelineOperator(err) {
+		// $bsonSize is only available since MongoDB 4.4
+		delete(group, "bytes")
+		churn, err = aggregateOplogChurn(client, since, group)
+	}
+	return churn, err
+}
+
+// OplogChurnCache refreshes the oplog churn at most once per window:
+// before MongoDB 4.4 the aggregation scans the whole oplog, which is too expensive for every scrape.
+type OplogChurnCache struct {
+	m         sync.Mutex
+	window    time.Duration
+	churn     []OplogChurnStats
+	refreshed time.Time
+	aggregate func(client *mongo.Client, window time.Duration) ([]OplogChurnStats, error)
+}
+
+// NewOplogChurnCache creates a new OplogChurnCache for the given window.
+func NewOplogChurnCache(window time.Duration) *OplogChurnCache {
+	return &OplogChurnCache{
+		window:    window,
+		aggregate: GetOplogChurn,
+	}
+}
+
+// Get returns the oplog churn, aggregated again if the last aggregation is older than the window.
+// The previous churn is kept if the aggregation fails.
+func (cache *OplogChurnCache) Get(client *mongo.Client, now time.Time) []OplogChurnStats {
+	cache.m.Lock()
+	defer cache.m.Unlock()
+
+	if !cache.refreshed.IsZero() && now.Sub(cache.refreshed) < cache.window {
+		return cache.churn
+	}
+	cache.refreshed = now
+	churn, err := cache.aggregate(client, cache.window)
+	if err != nil {
+		log.Errorf("Failed to get oplog churn: %s", err)
+		return cache.churn
+	}
+	cache.churn = churn
+	return churn
+}
+
+func aggregateOplogChurn(client *mongo.Client, since primitive.Timestamp, group bson.M) ([]OplogChurnStats, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{"ts": bson.M{"$gte": since}}},
+		{"$group": group},
+	}
+	opts := options.Aggregate().SetComment(shared.GetCallerLocation())
+	c, err := client.Database(oplogDb).Collection(oplogCollection).Aggregate(context.TODO(), pipeline, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := c.Close(context.TODO()); err != nil {
+			log.Errorf("Could not close Aggregate() cursor, reason: %v", err)
+		}
+	}()
+
+	var churn []OplogChurnStats
+	for c.Next(context.TODO()) {
+		stats := OplogChurnStats{}
+		if err := c.Decode(&stats); err != nil {
+			return nil, err
+		}
+		churn = append(churn, stats)
+	}
+	return churn, c.Err()
+}
+
 func (status *OplogStatus) Export(ch chan<- prometheus.Metric) {
 	oplogStatusSizeBytes.WithLabelValues("current").Set(0)
 	oplogStatusSizeBytes.WithLabelValues("storage").Set(0)
+	oplogStatusSizeBytes.WithLabelValues("max").Set(0)
+	oplogStatusFillRatio.Set(0)
 	if status.CollectionStats != nil {
 		oplogStatusCount.Set(status.CollectionStats.Count)
 		oplogStatusSizeBytes.WithLabelValues("current").Set(status.CollectionStats.Size)
 		oplogStatusSizeBytes.WithLabelValues("storage").Set(status.CollectionStats.StorageSize)
+		oplogStatusSizeBytes.WithLabelValues("max").Set(status.CollectionStats.MaxSize)
+		if status.CollectionStats.MaxSize > 0 {
+			oplogStatusFillRatio.Set(status.CollectionStats.Size / status.CollectionStats.MaxSize)
+		}
 	}
 	if status.OplogTimestamps != nil {
 		oplogStatusHeadTimestamp.Set(status.OplogTimestamps.Head)
 		oplogStatusTailTimestamp.Set(status.OplogTimestamps.Tail)
+		oplogStatusWindowSeconds.Set(status.OplogTimestamps.Head - status.OplogTimestamps.Tail)
+	}
+	for _, churn := range status.Churn {
+		ch <- prometheus.MustNewConstMetric(oplogChurnEntriesDesc, prometheus.GaugeValue, churn.Count, churn.ID.Op, churn.ID.Ns)
+		if churn.Bytes != nil {
+			ch <- prometheus.MustNewConstMetric(oplogChurnBytesDesc, prometheus.GaugeValue, *churn.Bytes, churn.ID.Op, churn.ID.Ns)
+		}
 	}
 
 	oplogStatusCount.Collect(ch)
 	oplogStatusHeadTimestamp.Collect(ch)
 	oplogStatusTailTimestamp.Collect(ch)
 	oplogStatusSizeBytes.Collect(ch)
+	oplogStatusWindowSeconds.Collect(ch)
+	oplogStatusFillRatio.Collect(ch)
 }
 
 func (status *OplogStatus) Describe(ch chan<- *prometheus.Desc) {
@@ -135,10 +278,15 @@ func (status *OplogStatus) Describe(ch chan<- *prometheus.Desc) {
 	oplogStatusHeadTimestamp.Describe(ch)
 	oplogStatusTailTimestamp.Describe(ch)
 	oplogStatusSizeBytes.Describe(ch)
+	oplogStatusWindowSeconds.Describe(ch)
+	oplogStatusFillRatio.Describe(ch)
+	ch <- oplogChurnEntriesDesc
+	ch <- oplogChurnBytesDesc
 }
 
 // GetOplogStatus gets oplog status.
-func GetOplogStatus(client *mongo.Client) *OplogStatus {
+// The oplog churn is not collected if churnCache is nil.
+func GetOplogStatus(client *mongo.Client, churnCache *OplogChurnCache) *OplogStatus {
 	collectionStats, err := GetOplogCollectionStats(client)
 	if err != nil {
 		log.Errorf("Failed to get oplog collection status: %s", err)
@@ -150,5 +298,10 @@ func GetOplogStatus(client *mongo.Client) *OplogStatus {
 		return nil
 	}
 
-	return &OplogStatus{CollectionStats: collectionStats, OplogTimestamps: oplogTimestamps}
+	var churn []OplogChurnStats
+	if churnCache != nil {
+		churn = churnCache.Get(client, time.Now())
+	}
+
+	return &OplogStatus{CollectionStats: collectionStats, OplogTimestamps: oplogTimestamps, Churn: churn}
 }
@@ -0,0 +1,91 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/percona/mongodb_exporter/testutils"
+)
+
+func TestOplogStatusExport(t *testing.T) {
+	var churn []OplogChurnStats
+	for _, doc := range []bson.M{
+		{"_id": bson.M{"op": "i", "ns": "test.coll"}, "count": int32(100), "bytes": int64(12000)},
+		{"_id": bson.M{"op": "n", "ns": ""}, "count": int32(6)},
+	} {
+		stats := OplogChurnStats{}
+		testutils.MustDecodeBSON(t, doc, &stats)
+		churn = append(churn, stats)
+	}
+
+	status := &OplogStatus{
+		OplogTimestamps: &OplogTimestamps{Head: 1561982400, Tail: 1561896000},
+		CollectionStats: &OplogCollectionStats{Count: 1000, Size: 256, StorageSize: 128, MaxSize: 1024},
+		Churn:           churn,
+	}
+	values := testutils.CollectMetrics(status.Export, "type", "op", "ns")
+
+	assert.Equal(t, 86400.0, values["mongodb_mongod_replset_oplog_window_seconds"])
+	assert.Equal(t, 1024.0, values["mongodb_mongod_replset_oplog_size_bytes/max"])
+	assert.Equal(t, 0.25, values["mongodb_mongod_replset_oplog_fill_ratio"])
+	assert.Equal(t, 100.0, values["mongodb_mongod_replset_oplog_churn_entries/i/test.coll"])
+	assert.Equal(t, 12000.0, values["mongodb_mongod_replset_oplog_churn_bytes/i/test.coll"])
+	assert.Equal(t, 6.0, values["mongodb_mongod_replset_oplog_churn_entries/n/"])
+	assert.NotContains(t, values, "mongodb_mongod_replset_oplog_churn_bytes/n/")
+}
+
+func TestOplogChurnCache(t *testing.T) {
+	var calls int
+	failing := false
+	cache := NewOplogChurnCache(time.Minute)
+	cache.aggregate = func(client *mongo.Client, window time.Duration) ([]OplogChurnStats, error) {
+		calls++
+		if failing {
+			return nil, errors.New("aggregation failed")
+		}
+		stats := OplogChurnStats{Count: float64(calls)}
+		return []OplogChurnStats{stats}, nil
+	}
+
+	now := time.Now()
+	assert.Equal(t, 1.0, cache.Get(nil, now)[0].Count)
+	// not aggregated again within the window
+	assert.Equal(t, 1.0, cache.Get(nil, now.Add(59*time.Second))[0].Count)
+	assert.Equal(t, 1, calls)
+
+	assert.Equal(t, 2.0, cache.Get(nil, now.Add(time.Minute))[0].Count)
+	assert.Equal(t, 2, calls)
+
+	// the previous churn is kept on failure, and not retried before the window elapsed
+	failing = true
+	assert.Equal(t, 2.0, cache.Get(nil, now.Add(2*time.Minute))[0].Count)
+	assert.Equal(t, 2.0, cache.Get(nil, now.Add(2*time.Minute+time.Second))[0].Count)
+	assert.Equal(t, 3, calls)
+}
+
+func TestIsInvalidPipelineOperator(t *testing.T) {
+	assert.True(t, isInvalidPipelineOperator(mongo.CommandError{Code: 168, Message: "Unrecognized expression '$bsonSize'"}))
+	assert.True(t, isInvalidPipelineOperator(mongo.CommandError{Name: "InvalidPipelineOperator"}))
+	assert.False(t, isInvalidPipelineOperator(mongo.CommandError{Code: 13, Name: "Unauthorized"}))
+	assert.False(t, isInvalidPipelineOperator(errors.New("connection reset")))
+	assert.False(t, isInvalidPipelineOperator(nil))
+}
@@ -2,6 +2,8 @@ package mongod
 
 import (
 	"context"
+	"strconv"
+	"strings"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/log"
@@ -44,6 +46,20 @@ var (
 		[]string{"id", "host"},
 		nil,
 	)
+
+	memberDelayDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "member_delay_seconds"),
+		"This field conveys the configured replication delay (slaveDelay or secondaryDelaySecs) of a given member",
+		[]string{"id", "host"},
+		nil,
+	)
+
+	chainingAllowedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "chaining_allowed"),
+		"This field conveys if secondaries may replicate from other secondaries (1) or only from the primary (0).",
+		[]string{"id"},
+		nil,
+	)
 )
 
 // OuterReplSetConf Although the docs say that it returns a map with id etc. it *actually* returns that wrapped in a map.
@@ -53,23 +69,63 @@ type OuterReplSetConf struct {
 
 // ReplSetConf keeps the data returned by the GetReplSetConf method
 type ReplSetConf struct {
-	ID      string       `bson:"_id"`
-	Version int          `bson:"version"`
-	Members []MemberConf `bson:"members"`
+	ID       string           `bson:"_id"`
+	Version  int              `bson:"version"`
+	Members  []MemberConf     `bson:"members"`
+	Settings *ReplSetSettings `bson:"settings,omitempty"`
+}
+
+// ReplSetSettings represents the settings of ReplSetConf
+type ReplSetSettings struct {
+	ChainingAllowed *bool `bson:"chainingAllowed,omitempty"`
 }
 
 // MemberConf represents an array element of ReplSetConf.Members
 type MemberConf struct {
-	ID           int32  `bson:"_id"`
-	Host         string `bson:"host"`
-	ArbiterOnly  bool   `bson:"arbiterOnly"`
-	BuildIndexes bool   `bson:"buildIndexes"`
-	Hidden       bool   `bson:"hidden"`
-	Priority     int32  `bson:"priority"`
-
-	Tags       map[string]string `bson:"tags"`
-	SlaveDelay float64           `bson:"saveDelay"`
-	Votes      int32             `bson:"votes"`
+	ID           int32   `bson:"_id"`
+	Host         string  `bson:"host"`
+	ArbiterOnly  bool    `bson:"arbiterOnly"`
+	BuildIndexes bool    `bson:"buildIndexes"`
+	Hidden       bool    `bson:"hidden"`
+	Priority     float64 `bson:"priority"`
+
+	Tags               map[string]string `bson:"tags"`
+	SlaveDelay         float64           `bson:"slaveDelay"`
+	SecondaryDelaySecs *float64          `bson:"secondaryDelaySecs,omitempty"`
+	Votes              int32             `bson:"votes"`
+}
+
+// Delay returns the configured replication delay of the member in seconds.
+func (member *MemberConf) Delay() float64 {
+	// secondaryDelaySecs replaces slaveDelay since version 5.0
+	if member.SecondaryDelaySecs != nil {
+		return *member.SecondaryDelaySecs
+	}
+	return member.SlaveDelay
+}
+
+// memberInfoDesc returns the member info descriptor with a label for each of the promoted tag keys.
+func memberInfoDesc(tagKeys []string) *prometheus.Desc {
+	labels := []string{"set", "name", "hidden", "arbiter", "priority", "votes", "delayed"}
+	for _, key := range tagKeys {
+		labels = append(labels, tagLabelName(key))
+	}
+	return prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "member_info"),
+		"The configuration of a given member, with the promoted replica set tags as tag_* labels.",
+		labels,
+		nil,
+	)
+}
+
+// tagLabelName converts a replica set tag key to a valid label name.
+func tagLabelName(key string) string {
+	return "tag_" + strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, key)
 }
 
 // Export exports the replSetGetStatus stati to be consumed by prometheus
@@ -95,7 +151,15 @@ func (replConf *ReplSetConf) Export(ch chan<- prometheus.Metric) {
 
 		ch <- prometheus.MustNewConstMetric(memberPriorityDesc, prometheus.GaugeValue, float64(member.Priority), replConf.ID, member.Host)
 		ch <- prometheus.MustNewConstMetric(memberVotesDesc, prometheus.GaugeValue, float64(member.Votes), replConf.ID, member.Host)
+		ch <- prometheus.MustNewConstMetric(memberDelayDesc, prometheus.GaugeValue, member.Delay(), replConf.ID, member.Host)
 	}
+
+	// chaining is allowed by default
+	chainingAllowed := 1.0
+	if replConf.Settings != nil && replConf.Settings.ChainingAllowed != nil && !*replConf.Settings.ChainingAllowed {
+		chainingAllowed = 0
+	}
+	ch <- prometheus.MustNewConstMetric(chainingAllowedDesc, prometheus.GaugeValue, chainingAllowed, replConf.ID)
 }
 
 // Describe describes the replSetGetStatus metrics for prometheus
@@ -105,6 +169,38 @@ func (replConf *ReplSetConf) Describe(ch chan<- *prometheus.Desc) {
 	ch <- memberBuildIndexesDesc
 	ch <- memberPriorityDesc
 	ch <- memberVotesDesc
+	ch <- memberDelayDesc
+	ch <- chainingAllowedDesc
+}
+
+// ExportMemberInfo exports an info metric for every member, promoting the given tag keys to labels.
+func (replConf *ReplSetConf) ExportMemberInfo(ch chan<- prometheus.Metric, tagKeys []string) {
+	// tag keys mapping to the same label name are promoted once
+	var keys []string
+	seen := make(map[string]bool)
+	for _, key := range tagKeys {
+		if name := tagLabelName(key); !seen[name] {
+			seen[name] = true
+			keys = append(keys, key)
+		}
+	}
+
+	desc := memberInfoDesc(keys)
+	for _, member := range replConf.Members {
+		labelValues := []string{
+			replConf.ID,
+			member.Host,
+			strconv.FormatBool(member.Hidden),
+			strconv.FormatBool(member.ArbiterOnly),
+			strconv.FormatFloat(member.Priority, 'f', -1, 64),
+			strconv.Itoa(int(member.Votes)),
+			strconv.FormatBool(member.Delay() > 0),
+		}
+		for _, key := range keys {
+			labelValues = append(labelValues, member.Tags[key])
+		}
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 1, labelValues...)
+	}
 }
 
 // GetReplSetConf returns the replica status info
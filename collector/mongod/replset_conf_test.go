@@ -19,7 +19,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/percona/exporter_shared/helpers"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
 
 	"github.com/percona/mongodb_exporter/testutils"
 )
@@ -37,3 +41,67 @@ func TestGetReplSetConfDecodesFine(t *testing.T) {
 	// test
 	assert.NotNil(t, status)
 }
+
+func TestReplSetConfChainingAllowed(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		settings bson.M
+		expected float64
+	}{
+		{"default", nil, 1},
+		{"allowed", bson.M{"chainingAllowed": true}, 1},
+		{"disallowed", bson.M{"chainingAllowed": false}, 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := bson.M{"config": bson.M{"_id": "rs0", "version": int32(1), "members": []bson.M{}}}
+			if tt.settings != nil {
+				doc["config"].(bson.M)["settings"] = tt.settings
+			}
+			outer := &OuterReplSetConf{}
+			testutils.MustDecodeBSON(t, doc, outer)
+
+			assert.Equal(t, map[string]float64{
+				"mongodb_mongod_replset_chaining_allowed": tt.expected,
+			}, testutils.CollectMetrics(outer.Config.Export))
+		})
+	}
+}
+
+func TestReplSetConfMemberInfo(t *testing.T) {
+	outer := &OuterReplSetConf{}
+	testutils.MustDecodeBSON(t, bson.M{"config": bson.M{"_id": "rs0", "version": int32(1), "members": []bson.M{
+		{"_id": int32(0), "host": "rs1:27017", "priority": 1.5, "votes": int32(1), "slaveDelay": int64(0), "tags": bson.M{"dc": "east", "rack.id": "r1"}},
+		{"_id": int32(1), "host": "rs2:27017", "priority": 0.0, "votes": int32(1), "hidden": true, "slaveDelay": int64(3600), "tags": bson.M{"dc": "west"}},
+		{"_id": int32(2), "host": "rs3:27017", "priority": 0.0, "votes": int32(0), "hidden": true, "secondaryDelaySecs": int64(7200)},
+	}}}, outer)
+	conf := outer.Config
+
+	assert.Equal(t, 1.5, conf.Members[0].Priority)
+	assert.Equal(t, 0.0, conf.Members[0].Delay())
+	assert.Equal(t, 3600.0, conf.Members[1].Delay())
+	assert.Equal(t, 7200.0, conf.Members[2].Delay())
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		conf.ExportMemberInfo(ch, []string{"dc", "rack.id", "rack_id"})
+		close(ch)
+	}()
+	infos := make(map[string]map[string]string)
+	for m := range ch {
+		metric := helpers.ReadMetric(m)
+		assert.Equal(t, "mongodb_mongod_replset_member_info", metric.Name)
+		assert.Equal(t, 1.0, metric.Value)
+		infos[metric.Labels["name"]] = metric.Labels
+	}
+
+	require.Len(t, infos, 3)
+	assert.Equal(t, map[string]string{
+		"set": "rs0", "name": "rs1:27017", "hidden": "false", "arbiter": "false", "priority": "1.5", "votes": "1", "delayed": "false",
+		"tag_dc": "east", "tag_rack_id": "r1",
+	}, infos["rs1:27017"])
+	assert.Equal(t, "true", infos["rs2:27017"]["delayed"])
+	assert.Equal(t, "west", infos["rs2:27017"]["tag_dc"])
+	assert.Equal(t, "", infos["rs2:27017"]["tag_rack_id"])
+	assert.Equal(t, "true", infos["rs3:27017"]["delayed"])
+	assert.Equal(t, "0", infos["rs3:27017"]["votes"])
+}
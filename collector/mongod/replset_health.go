@@ -0,0 +1,201 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	votingMembersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "voting_members"),
+		"The number of voting members in the replica set configuration.",
+		[]string{"set"},
+		nil,
+	)
+	votingMembersHealthyDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "voting_members_healthy"),
+		"The number of voting members which are reachable and able to vote.",
+		[]string{"set"},
+		nil,
+	)
+	votingMajorityDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "voting_majority"),
+		"The number of votes needed to elect a primary.",
+		[]string{"set"},
+		nil,
+	)
+	votingMajorityAvailableDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "voting_majority_available"),
+		"Whether the healthy voting members are a majority (1) or not (0).",
+		[]string{"set"},
+		nil,
+	)
+	writeMajorityDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "write_majority"),
+		"The number of data-bearing voting members which must acknowledge a w:majority write.",
+		[]string{"set"},
+		nil,
+	)
+	writeMajorityMembersHealthyDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "write_majority_members_healthy"),
+		"The number of data-bearing voting members which are PRIMARY or SECONDARY and can acknowledge writes.",
+		[]string{"set"},
+		nil,
+	)
+	writeMajorityAvailableDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "write_majority_available"),
+		"Whether w:majority writes can currently be acknowledged (1) or not (0).",
+		[]string{"set"},
+		nil,
+	)
+	electionPossibleDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "election_possible"),
+		"Whether a new primary could be elected if the current one failed (1) or not (0).",
+		[]string{"set"},
+		nil,
+	)
+	electableSecondariesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "electable_secondaries"),
+		"The number of healthy secondaries with a non-zero priority.",
+		[]string{"set"},
+		nil,
+	)
+)
+
+// votingStates are the member states in which a member can vote in an election.
+var votingStates = map[string]bool{
+	"PRIMARY":    true,
+	"SECONDARY":  true,
+	"RECOVERING": true,
+	"STARTUP2":   true,
+	"ARBITER":    true,
+	"ROLLBACK":   true,
+}
+
+// ReplSetHealth joins the replica set configuration and status to tell whether the set
+// can elect a primary and acknowledge w:majority writes.
+type ReplSetHealth struct {
+	Set string
+
+	VotingMembers        int
+	VotingMembersHealthy int
+	VotingMajority       int
+
+	WriteMajority               int
+	WriteMajorityMembersHealthy int
+
+	HasPrimary           bool
+	PrimaryVotes         int
+	ElectableSecondaries int
+}
+
+// NewReplSetHealth computes the replica set health from its configuration and status.
+func NewReplSetHealth(conf *ReplSetConf, status *ReplSetStatus) *ReplSetHealth {
+	health := &ReplSetHealth{Set: status.Set}
+
+	members := make(map[string]*Member, len(status.Members))
+	for i := range status.Members {
+		members[status.Members[i].Name] = &status.Members[i]
+	}
+
+	var dataBearingVoters int
+	for _, memberConf := range conf.Members {
+		member := members[memberConf.Host]
+		healthy := member != nil && (member.Health == nil || *member.Health == 1)
+
+		if memberConf.Votes > 0 {
+			health.VotingMembers++
+			if !memberConf.ArbiterOnly {
+				dataBearingVoters++
+			}
+			if healthy && votingStates[member.StateStr] {
+				health.VotingMembersHealthy++
+			}
+			if healthy && !memberConf.ArbiterOnly && (member.StateStr == "PRIMARY" || member.StateStr == "SECONDARY") {
+				health.WriteMajorityMembersHealthy++
+			}
+		}
+
+		if !healthy {
+			continue
+		}
+		switch member.StateStr {
+		case "PRIMARY":
+			health.HasPrimary = true
+			health.PrimaryVotes = int(memberConf.Votes)
+		case "SECONDARY":
+			if memberConf.Priority > 0 {
+				health.ElectableSecondaries++
+			}
+		}
+	}
+
+	health.VotingMajority = health.VotingMembers/2 + 1
+	// w:majority is the lesser of the voting majority and the number of data-bearing voters
+	health.WriteMajority = health.VotingMajority
+	if dataBearingVoters < health.WriteMajority {
+		health.WriteMajority = dataBearingVoters
+	}
+	return health
+}
+
+// VotingMajorityAvailable returns true if the healthy voting members are a majority.
+func (health *ReplSetHealth) VotingMajorityAvailable() bool {
+	return health.VotingMembersHealthy >= health.VotingMajority
+}
+
+// WriteMajorityAvailable returns true if w:majority writes can be acknowledged.
+func (health *ReplSetHealth) WriteMajorityAvailable() bool {
+	return health.HasPrimary && health.WriteMajorityMembersHealthy >= health.WriteMajority
+}
+
+// ElectionPossible returns true if a secondary could be elected without the current primary.
+func (health *ReplSetHealth) ElectionPossible() bool {
+	return health.ElectableSecondaries > 0 && health.VotingMembersHealthy-health.PrimaryVotes >= health.VotingMajority
+}
+
+// Export exports the replica set health to be consumed by prometheus
+func (health *ReplSetHealth) Export(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(votingMembersDesc, prometheus.GaugeValue, float64(health.VotingMembers), health.Set)
+	ch <- prometheus.MustNewConstMetric(votingMembersHealthyDesc, prometheus.GaugeValue, float64(health.VotingMembersHealthy), health.Set)
+	ch <- prometheus.MustNewConstMetric(votingMajorityDesc, prometheus.GaugeValue, float64(health.VotingMajority), health.Set)
+	ch <- prometheus.MustNewConstMetric(votingMajorityAvailableDesc, prometheus.GaugeValue, boolToFloat64(health.VotingMajorityAvailable()), health.Set)
+	ch <- prometheus.MustNewConstMetric(writeMajorityDesc, prometheus.GaugeValue, float64(health.WriteMajority), health.Set)
+	ch <- prometheus.MustNewConstMetric(writeMajorityMembersHealthyDesc, prometheus.GaugeValue, float64(health.WriteMajorityMembersHealthy), health.Set)
+	ch <- prometheus.MustNewConstMetric(writeMajorityAvailableDesc, prometheus.GaugeValue, boolToFloat64(health.WriteMajorityAvailable()), health.Set)
+	ch <- prometheus.MustNewConstMetric(electionPossibleDesc, prometheus.GaugeValue, boolToFloat64(health.ElectionPossible()), health.Set)
+	ch <- prometheus.MustNewConstMetric(electableSecondariesDesc, prometheus.GaugeValue, float64(health.ElectableSecondaries), health.Set)
+}
+
+// Describe describes the replica set health metrics for prometheus
+func (health *ReplSetHealth) Describe(ch chan<- *prometheus.Desc) {
+	ch <- votingMembersDesc
+	ch <- votingMembersHealthyDesc
+	ch <- votingMajorityDesc
+	ch <- votingMajorityAvailableDesc
+	ch <- writeMajorityDesc
+	ch <- writeMajorityMembersHealthyDesc
+	ch <- writeMajorityAvailableDesc
+	ch <- electionPossibleDesc
+	ch <- electableSecondariesDesc
+}
+
+func boolToFloat64(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
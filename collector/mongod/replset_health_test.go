@@ -0,0 +1,113 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// replSetHealthFixture builds a configuration and a status from the member states, keyed by host.
+func replSetHealthFixture(confs []MemberConf, states map[string]string) (*ReplSetConf, *ReplSetStatus) {
+	conf := &ReplSetConf{ID: "rs0", Members: confs}
+	status := &ReplSetStatus{Set: "rs0"}
+	for _, memberConf := range confs {
+		health := int32(1)
+		state := states[memberConf.Host]
+		if state == "(not reachable/healthy)" {
+			health = 0
+		}
+		status.Members = append(status.Members, Member{Name: memberConf.Host, Health: &health, StateStr: state})
+	}
+	return conf, status
+}
+
+func TestReplSetHealth(t *testing.T) {
+	psa := []MemberConf{
+		{Host: "rs1:27017", Priority: 1, Votes: 1},
+		{Host: "rs2:27017", Priority: 1, Votes: 1},
+		{Host: "rs3:27017", ArbiterOnly: true, Votes: 1},
+	}
+	pss := []MemberConf{
+		{Host: "rs1:27017", Priority: 1, Votes: 1},
+		{Host: "rs2:27017", Priority: 1, Votes: 1},
+		{Host: "rs3:27017", Priority: 0, Hidden: true, Votes: 1},
+		{Host: "rs4:27017", Priority: 0, Votes: 0},
+	}
+
+	for _, tc := range []struct {
+		name                  string
+		confs                 []MemberConf
+		states                map[string]string
+		healthy, writeHealthy int
+		writeMajority         int
+		votingMajority        bool
+		writable              bool
+		electionPossible      bool
+		electableSecondaries  int
+	}{
+		{
+			name:  "PSA healthy",
+			confs: psa,
+			states: map[string]string{
+				"rs1:27017": "PRIMARY", "rs2:27017": "SECONDARY", "rs3:27017": "ARBITER",
+			},
+			healthy: 3, writeHealthy: 2, writeMajority: 2,
+			votingMajority: true, writable: true, electionPossible: true, electableSecondaries: 1,
+		},
+		{
+			name:  "PSA secondary down",
+			confs: psa,
+			states: map[string]string{
+				"rs1:27017": "PRIMARY", "rs2:27017": "(not reachable/healthy)", "rs3:27017": "ARBITER",
+			},
+			healthy: 2, writeHealthy: 1, writeMajority: 2,
+			votingMajority: true, writable: false, electionPossible: false, electableSecondaries: 0,
+		},
+		{
+			name:  "PSS with hidden and non-voting members",
+			confs: pss,
+			states: map[string]string{
+				"rs1:27017": "PRIMARY", "rs2:27017": "SECONDARY", "rs3:27017": "SECONDARY", "rs4:27017": "SECONDARY",
+			},
+			healthy: 3, writeHealthy: 3, writeMajority: 2,
+			votingMajority: true, writable: true, electionPossible: true, electableSecondaries: 1,
+		},
+		{
+			name:  "PSS without primary",
+			confs: pss,
+			states: map[string]string{
+				"rs1:27017": "(not reachable/healthy)", "rs2:27017": "SECONDARY", "rs3:27017": "RECOVERING", "rs4:27017": "SECONDARY",
+			},
+			healthy: 2, writeHealthy: 1, writeMajority: 2,
+			votingMajority: true, writable: false, electionPossible: true, electableSecondaries: 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			health := NewReplSetHealth(replSetHealthFixture(tc.confs, tc.states))
+
+			assert.Equal(t, 3, health.VotingMembers)
+			assert.Equal(t, 2, health.VotingMajority)
+			assert.Equal(t, tc.healthy, health.VotingMembersHealthy)
+			assert.Equal(t, tc.writeHealthy, health.WriteMajorityMembersHealthy)
+			assert.Equal(t, tc.writeMajority, health.WriteMajority)
+			assert.Equal(t, tc.votingMajority, health.VotingMajorityAvailable())
+			assert.Equal(t, tc.writable, health.WriteMajorityAvailable())
+			assert.Equal(t, tc.electionPossible, health.ElectionPossible())
+			assert.Equal(t, tc.electableSecondaries, health.ElectableSecondaries)
+		})
+	}
+}
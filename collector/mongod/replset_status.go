@@ -21,6 +21,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/log"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
@@ -129,6 +130,54 @@ var (
 		Name:      "member_config_version",
 		Help:      "The configVersion value is the replica set configuration version.",
 	}, []string{"set", "name", "state"})
+	optimeTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "optime_timestamp",
+		Help:      "The timestamp of the majority commit point, the majority read point and the last applied and durable oplog entries of the current member",
+	}, []string{"set", "type"})
+	majorityCommitLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "majority_commit_lag_seconds",
+		Help:      "The time in seconds between the last oplog entry applied by the current member and the majority commit point",
+	}, []string{"set"})
+	memberOptimeDurableDate = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "member_optime_durable_date",
+		Help:      "The timestamp of the last oplog entry that this member wrote to the journal.",
+	}, []string{"set", "name", "state"})
+	memberDurableLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "member_durable_lag",
+		Help:      "The time in seconds between the last oplog entry this member applied and the last one it wrote to the journal.",
+	}, []string{"set", "name", "state"})
+	memberEffectiveRepLagDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "member_effective_replication_lag"),
+		"The replication lag in seconds of a secondary minus its configured replication delay.",
+		[]string{"set", "name", "state"},
+		nil,
+	)
+	memberSyncSourceInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "member_sync_source_info"),
+		"The member this member replicates from.",
+		[]string{"set", "name", "sync_source"},
+		nil,
+	)
+	memberChainDepthDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "member_chain_depth"),
+		"The number of replication hops between the primary and this member, 0 for the primary.",
+		[]string{"set", "name"},
+		nil,
+	)
+	memberSyncEdgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "member_sync_edge"),
+		"An edge of the replication topology from the sync source to the member it feeds; the value is the lag in seconds of the target behind the source.",
+		[]string{"set", "source", "target"},
+		nil,
+	)
 	primaryOptimeDate        float64
 	primaryLastHeartbeatRecv float64
 )
@@ -141,10 +190,56 @@ type ReplSetStatus struct {
 	Term                    *int32    `bson:"term,omitempty"`
 	HeartbeatIntervalMillis *float64  `bson:"heartbeatIntervalMillis,omitempty"`
 	Members                 []Member  `bson:"members"`
+	Optimes                 *Optimes  `bson:"optimes,omitempty"`
+
+	ElectionCandidateMetrics   *ElectionCandidateMetrics   `bson:"electionCandidateMetrics,omitempty"`
+	ElectionParticipantMetrics *ElectionParticipantMetrics `bson:"electionParticipantMetrics,omitempty"`
+
+	InitialSyncStatus *InitialSyncStatus `bson:"initialSyncStatus,omitempty"`
 
 	Ok float64 `bson:"ok"`
 }
 
+// Optimes represents the optimes of the current member (new in version 3.4)
+type Optimes struct {
+	LastCommittedOpTime       *OpTime `bson:"lastCommittedOpTime,omitempty"`
+	ReadConcernMajorityOpTime *OpTime `bson:"readConcernMajorityOpTime,omitempty"`
+	AppliedOpTime             *OpTime `bson:"appliedOpTime,omitempty"`
+	DurableOpTime             *OpTime `bson:"durableOpTime,omitempty"`
+}
+
+// OpTime represents an optime, a {ts, t} document with protocolVersion 1 or a bare timestamp with protocolVersion 0
+type OpTime struct {
+	Ts primitive.Timestamp
+	T  int64
+}
+
+// UnmarshalBSONValue decodes both optime formats.
+func (opTime *OpTime) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	v := bson.RawValue{Type: t, Value: data}
+	if ts, i, ok := v.TimestampOK(); ok {
+		opTime.Ts = primitive.Timestamp{T: ts, I: i}
+		return nil
+	}
+	doc, ok := v.DocumentOK()
+	if !ok {
+		return nil
+	}
+	if ts, i, ok := doc.Lookup("ts").TimestampOK(); ok {
+		opTime.Ts = primitive.Timestamp{T: ts, I: i}
+	}
+	if term, ok := rawValueToFloat64(doc.Lookup("t")); ok {
+		opTime.T = int64(term)
+	}
+	return nil
+}
+
+// IsNull returns true for a null optime, like the last committed optime of a member without a majority
+// or during initial sync ({ts: Timestamp(0, 0), t: -1}).
+func (opTime *OpTime) IsNull() bool {
+	return opTime == nil || opTime.Ts.T == 0
+}
+
 // Member represents an array element of ReplSetStatus.Members
 type Member struct {
 	Name                 string              `bson:"name"`
@@ -155,6 +250,7 @@ type Member struct {
 	Uptime               float64             `bson:"uptime"`
 	Optime               interface{}         `bson:"optime"`
 	OptimeDate           time.Time           `bson:"optimeDate"`
+	OptimeDurableDate    *time.Time          `bson:"optimeDurableDate,omitempty"`
 	ElectionTime         primitive.Timestamp `bson:"electionTime,omitempty"`
 	ElectionDate         *time.Time          `bson:"electionDate,omitempty"`
 	LastHeartbeat        *time.Time          `bson:"lastHeartbeat,omitempty"`
@@ -162,9 +258,83 @@ type Member struct {
 	LastHeartbeatMessage *string             `bson:"lastHeartbeatMessage,omitempty"`
 	PingMs               *float64            `bson:"pingMs,omitempty"`
 	SyncingTo            *string             `bson:"syncingTo,omitempty"`
+	SyncSourceHost       *string             `bson:"syncSourceHost,omitempty"`
 	ConfigVersion        *int32              `bson:"configVersion,omitempty"`
 }
 
+// SyncSource returns the member this member replicates from, empty if none.
+func (member *Member) SyncSource() string {
+	// syncSourceHost replaces syncingTo since version 4.0
+	if member.SyncSourceHost != nil {
+		return *member.SyncSourceHost
+	}
+	if member.SyncingTo != nil {
+		return *member.SyncingTo
+	}
+	return ""
+}
+
+// exportTopology exports the sync sources, chain depths and replication edges of the members.
+func (replStatus *ReplSetStatus) exportTopology(ch chan<- prometheus.Metric) {
+	members := make(map[string]*Member, len(replStatus.Members))
+	for i := range replStatus.Members {
+		members[replStatus.Members[i].Name] = &replStatus.Members[i]
+	}
+
+	for _, member := range replStatus.Members {
+		source := member.SyncSource()
+		if source != "" {
+			ch <- prometheus.MustNewConstMetric(memberSyncSourceInfoDesc, prometheus.GaugeValue, 1, replStatus.Set, member.Name, source)
+			if sourceMember, ok := members[source]; ok {
+				lag := sourceMember.OptimeDate.Sub(member.OptimeDate).Seconds()
+				ch <- prometheus.MustNewConstMetric(memberSyncEdgeDesc, prometheus.GaugeValue, lag, replStatus.Set, source, member.Name)
+			}
+		}
+
+		// follow the sync sources up to the primary, giving up on broken or looping chains
+		depth := 0
+		current := &member
+		for current != nil && current.StateStr != "PRIMARY" && depth < len(replStatus.Members) {
+			current = members[current.SyncSource()]
+			depth++
+		}
+		if current != nil && current.StateStr == "PRIMARY" {
+			ch <- prometheus.MustNewConstMetric(memberChainDepthDesc, prometheus.GaugeValue, float64(depth), replStatus.Set, member.Name)
+		}
+	}
+}
+
+// ExportEffectiveReplicationLag exports the replication lag of the secondaries minus the delay
+// configured for them, so that delayed members are not reported as lagging.
+func (replStatus *ReplSetStatus) ExportEffectiveReplicationLag(ch chan<- prometheus.Metric, conf *ReplSetConf) {
+	delays := make(map[string]float64, len(conf.Members))
+	for _, member := range conf.Members {
+		delays[member.Host] = member.Delay()
+	}
+
+	var primary *Member
+	for i := range replStatus.Members {
+		if replStatus.Members[i].StateStr == "PRIMARY" {
+			primary = &replStatus.Members[i]
+			break
+		}
+	}
+	if primary == nil {
+		return
+	}
+
+	for _, member := range replStatus.Members {
+		if member.StateStr != "SECONDARY" {
+			continue
+		}
+		lag := primary.OptimeDate.Sub(member.OptimeDate).Seconds() - delays[member.Name]
+		if lag < 0 {
+			lag = 0
+		}
+		ch <- prometheus.MustNewConstMetric(memberEffectiveRepLagDesc, prometheus.GaugeValue, lag, replStatus.Set, member.Name, member.StateStr)
+	}
+}
+
 // Export exports the replSetGetStatus stati to be consumed by prometheus
 func (replStatus *ReplSetStatus) Export(ch chan<- prometheus.Metric) {
 	myName.Reset()
@@ -182,6 +352,10 @@ func (replStatus *ReplSetStatus) Export(ch chan<- prometheus.Metric) {
 	memberLastHeartbeatRecv.Reset()
 	memberPingMs.Reset()
 	memberConfigVersion.Reset()
+	optimeTimestamp.Reset()
+	majorityCommitLag.Reset()
+	memberOptimeDurableDate.Reset()
+	memberDurableLag.Reset()
 
 	myState.WithLabelValues(replStatus.Set).Set(float64(replStatus.MyState))
 	date.WithLabelValues(replStatus.Set).Set(float64(replStatus.Date.Unix()))
@@ -197,6 +371,23 @@ func (replStatus *ReplSetStatus) Export(ch chan<- prometheus.Metric) {
 		heartbeatIntervalMillis.WithLabelValues(replStatus.Set).Set(*replStatus.HeartbeatIntervalMillis)
 	}
 
+	// new in version 3.4
+	if optimes := replStatus.Optimes; optimes != nil {
+		for typ, opTime := range map[string]*OpTime{
+			"last_committed":        optimes.LastCommittedOpTime,
+			"read_concern_majority": optimes.ReadConcernMajorityOpTime,
+			"applied":               optimes.AppliedOpTime,
+			"durable":               optimes.DurableOpTime,
+		} {
+			if !opTime.IsNull() {
+				optimeTimestamp.WithLabelValues(replStatus.Set, typ).Set(float64(opTime.Ts.T))
+			}
+		}
+		if !optimes.AppliedOpTime.IsNull() && !optimes.LastCommittedOpTime.IsNull() {
+			majorityCommitLag.WithLabelValues(replStatus.Set).Set(float64(optimes.AppliedOpTime.Ts.T) - float64(optimes.LastCommittedOpTime.Ts.T))
+		}
+	}
+
 	// Find the Optime and the LastHeartbeatRecv for the Primary.
 	for _, member := range replStatus.Members {
 		if member.StateStr == "PRIMARY" {
@@ -237,6 +428,12 @@ func (replStatus *ReplSetStatus) Export(ch chan<- prometheus.Metric) {
 
 		memberOptimeDate.With(ls).Set(float64(member.OptimeDate.Unix()))
 
+		// new in version 3.4, not available for arbiters
+		if member.OptimeDurableDate != nil {
+			memberOptimeDurableDate.With(ls).Set(float64(member.OptimeDurableDate.Unix()))
+			memberDurableLag.With(ls).Set(float64(member.OptimeDate.Unix()) - float64(member.OptimeDurableDate.Unix()))
+		}
+
 		if member.StateStr == "SECONDARY" {
 			memberRepLag.With(ls).Set(primaryOptimeDate - float64(member.OptimeDate.Unix()))
 			memberOperationalLag.With(ls).Set(float64(replStatus.Date.Unix()) - primaryLastHeartbeatRecv)
@@ -259,6 +456,21 @@ func (replStatus *ReplSetStatus) Export(ch chan<- prometheus.Metric) {
 			memberConfigVersion.With(ls).Set(float64(*member.ConfigVersion))
 		}
 	}
+	replStatus.exportTopology(ch)
+
+	// new in version 4.2.1
+	if replStatus.ElectionCandidateMetrics != nil {
+		replStatus.ElectionCandidateMetrics.Export(ch, replStatus.Set)
+	}
+	if replStatus.ElectionParticipantMetrics != nil {
+		replStatus.ElectionParticipantMetrics.Export(ch, replStatus.Set)
+	}
+
+	// new in version 3.4, only returned with initialSync: 1
+	if replStatus.InitialSyncStatus != nil {
+		replStatus.InitialSyncStatus.Export(ch, replStatus.Set, replStatus.MyState)
+	}
+
 	// collect metrics
 	myName.Collect(ch)
 	myState.Collect(ch)
@@ -276,6 +488,10 @@ func (replStatus *ReplSetStatus) Export(ch chan<- prometheus.Metric) {
 	memberLastHeartbeatRecv.Collect(ch)
 	memberPingMs.Collect(ch)
 	memberConfigVersion.Collect(ch)
+	optimeTimestamp.Collect(ch)
+	majorityCommitLag.Collect(ch)
+	memberOptimeDurableDate.Collect(ch)
+	memberDurableLag.Collect(ch)
 }
 
 // Describe describes the replSetGetStatus metrics for prometheus
@@ -295,14 +511,38 @@ func (replStatus *ReplSetStatus) Describe(ch chan<- *prometheus.Desc) {
 	memberLastHeartbeatRecv.Describe(ch)
 	memberPingMs.Describe(ch)
 	memberConfigVersion.Describe(ch)
+	optimeTimestamp.Describe(ch)
+	majorityCommitLag.Describe(ch)
+	memberOptimeDurableDate.Describe(ch)
+	memberDurableLag.Describe(ch)
 
 	ch <- memberUptimeDesc
+	ch <- memberSyncSourceInfoDesc
+	ch <- memberChainDepthDesc
+	ch <- memberSyncEdgeDesc
+	ch <- memberEffectiveRepLagDesc
+	ch <- electionCandidateLastElectionDesc
+	ch <- electionCandidateTermDesc
+	ch <- electionCandidateVotesNeededDesc
+	ch <- electionCandidatePriorityDesc
+	ch <- electionCandidateCatchUpOpsDesc
+	ch <- electionCandidateWMajorityAvailableDesc
+	ch <- electionParticipantVotedDesc
+	ch <- electionParticipantTermDesc
+	ch <- electionParticipantLastVoteDesc
+	if replStatus.InitialSyncStatus != nil {
+		replStatus.InitialSyncStatus.Describe(ch)
+	}
 }
 
-// GetReplSetStatus returns the replica status info
-func GetReplSetStatus(client *mongo.Client) *ReplSetStatus {
+// GetReplSetStatus returns the replica status info, with the initial sync status if initialSync is true (3.4+).
+func GetReplSetStatus(client *mongo.Client, initialSync bool) *ReplSetStatus {
+	cmd := bson.D{{"replSetGetStatus", 1}}
+	if initialSync {
+		cmd = append(cmd, bson.E{"initialSync", 1})
+	}
 	result := &ReplSetStatus{}
-	err := client.Database("admin").RunCommand(context.TODO(), bson.D{{"replSetGetStatus", 1}}).Decode(result)
+	err := client.Database("admin").RunCommand(context.TODO(), cmd).Decode(result)
 	if err != nil {
 		log.Errorf("Failed to get replSet status: %s", err)
 		return nil
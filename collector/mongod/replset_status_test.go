@@ -19,7 +19,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"github.com/percona/mongodb_exporter/testutils"
 )
@@ -32,9 +35,135 @@ func TestGetReplSetStatusDecodesFine(t *testing.T) {
 	defer client.Disconnect(ctx)
 
 	// run
-	status := GetReplSetStatus(client)
+	status := GetReplSetStatus(client, true)
 
 	// test
 	assert.NotNil(t, status)
 	assert.Equal(t, 1.0, status.Ok)
 }
+
+func exportReplSetStatus(t *testing.T, doc bson.M) map[string]float64 {
+	status := &ReplSetStatus{}
+	testutils.MustDecodeBSON(t, doc, status)
+	return testutils.CollectMetrics(status.Export, "name", "type", "target", "phase")
+}
+
+func TestReplSetStatusOptimes(t *testing.T) {
+	applied := time.Unix(1561982400, 0)
+	values := exportReplSetStatus(t, bson.M{
+		"set":     "rs0",
+		"date":    applied,
+		"myState": int32(1),
+		"optimes": bson.M{
+			"lastCommittedOpTime":       bson.M{"ts": primitive.Timestamp{T: 1561982390, I: 1}, "t": int64(3)},
+			"readConcernMajorityOpTime": bson.M{"ts": primitive.Timestamp{T: 1561982390, I: 1}, "t": int64(3)},
+			"appliedOpTime":             bson.M{"ts": primitive.Timestamp{T: 1561982400, I: 2}, "t": int64(3)},
+			"durableOpTime":             bson.M{"ts": primitive.Timestamp{T: 1561982399, I: 1}, "t": int64(3)},
+		},
+		"members": []bson.M{
+			{"name": "rs1:27017", "state": int32(1), "stateStr": "PRIMARY", "self": true, "optimeDate": applied, "optimeDurableDate": applied.Add(-time.Second)},
+			{"name": "rs2:27017", "state": int32(2), "stateStr": "SECONDARY", "optimeDate": applied.Add(-5 * time.Second), "optimeDurableDate": applied.Add(-7 * time.Second)},
+			{"name": "rs3:27017", "state": int32(7), "stateStr": "ARBITER"},
+		},
+	})
+
+	assert.Equal(t, 1561982390.0, values["mongodb_mongod_replset_optime_timestamp/last_committed"])
+	assert.Equal(t, 1561982390.0, values["mongodb_mongod_replset_optime_timestamp/read_concern_majority"])
+	assert.Equal(t, 1561982400.0, values["mongodb_mongod_replset_optime_timestamp/applied"])
+	assert.Equal(t, 1561982399.0, values["mongodb_mongod_replset_optime_timestamp/durable"])
+	assert.Equal(t, 10.0, values["mongodb_mongod_replset_majority_commit_lag_seconds"])
+	assert.Equal(t, 1561982399.0, values["mongodb_mongod_replset_member_optime_durable_date/rs1:27017"])
+	assert.Equal(t, 1.0, values["mongodb_mongod_replset_member_durable_lag/rs1:27017"])
+	assert.Equal(t, 2.0, values["mongodb_mongod_replset_member_durable_lag/rs2:27017"])
+	assert.NotContains(t, values, "mongodb_mongod_replset_member_durable_lag/rs3:27017")
+}
+
+func TestReplSetStatusOptimesProtocolVersion0(t *testing.T) {
+	values := exportReplSetStatus(t, bson.M{
+		"set":     "rs0",
+		"myState": int32(1),
+		"optimes": bson.M{
+			"lastCommittedOpTime": primitive.Timestamp{T: 1561982390, I: 1},
+			"appliedOpTime":       primitive.Timestamp{T: 1561982400, I: 1},
+			"durableOpTime":       primitive.Timestamp{T: 1561982400, I: 1},
+		},
+	})
+
+	assert.Equal(t, 1561982400.0, values["mongodb_mongod_replset_optime_timestamp/applied"])
+	assert.Equal(t, 10.0, values["mongodb_mongod_replset_majority_commit_lag_seconds"])
+	assert.NotContains(t, values, "mongodb_mongod_replset_optime_timestamp/read_concern_majority")
+}
+
+func TestReplSetStatusNullOptimes(t *testing.T) {
+	for name, lastCommitted := range map[string]interface{}{
+		"null":      nil,
+		"null term": bson.M{"ts": primitive.Timestamp{}, "t": int64(-1)},
+	} {
+		values := exportReplSetStatus(t, bson.M{
+			"set":     "rs0",
+			"myState": int32(5),
+			"optimes": bson.M{
+				"lastCommittedOpTime":       lastCommitted,
+				"readConcernMajorityOpTime": bson.M{"ts": primitive.Timestamp{}, "t": int64(-1)},
+				"appliedOpTime":             bson.M{"ts": primitive.Timestamp{T: 1561982400, I: 1}, "t": int64(1)},
+			},
+		})
+
+		assert.Equal(t, 1561982400.0, values["mongodb_mongod_replset_optime_timestamp/applied"], name)
+		assert.NotContains(t, values, "mongodb_mongod_replset_optime_timestamp/last_committed", name)
+		assert.NotContains(t, values, "mongodb_mongod_replset_optime_timestamp/read_concern_majority", name)
+		assert.NotContains(t, values, "mongodb_mongod_replset_majority_commit_lag_seconds", name)
+	}
+}
+
+func TestReplSetStatusTopology(t *testing.T) {
+	now := time.Unix(1561982400, 0)
+	values := exportReplSetStatus(t, bson.M{
+		"set":     "rs0",
+		"myState": int32(2),
+		"members": []bson.M{
+			{"name": "dc1-a:27017", "state": int32(1), "stateStr": "PRIMARY", "optimeDate": now},
+			{"name": "dc1-b:27017", "state": int32(2), "stateStr": "SECONDARY", "optimeDate": now.Add(-time.Second), "syncSourceHost": "dc1-a:27017"},
+			{"name": "dc2-a:27017", "state": int32(2), "stateStr": "SECONDARY", "optimeDate": now.Add(-4 * time.Second), "syncingTo": "dc1-b:27017"},
+			{"name": "dc2-b:27017", "state": int32(2), "stateStr": "SECONDARY", "optimeDate": now.Add(-9 * time.Second), "syncSourceHost": "dc2-a:27017", "syncingTo": "dc2-a:27017"},
+			{"name": "dc3-a:27017", "state": int32(2), "stateStr": "SECONDARY", "optimeDate": now.Add(-time.Minute), "syncSourceHost": ""},
+		},
+	})
+
+	assert.Equal(t, 0.0, values["mongodb_mongod_replset_member_chain_depth/dc1-a:27017"])
+	assert.Equal(t, 1.0, values["mongodb_mongod_replset_member_chain_depth/dc1-b:27017"])
+	assert.Equal(t, 2.0, values["mongodb_mongod_replset_member_chain_depth/dc2-a:27017"])
+	assert.Equal(t, 3.0, values["mongodb_mongod_replset_member_chain_depth/dc2-b:27017"])
+	assert.NotContains(t, values, "mongodb_mongod_replset_member_chain_depth/dc3-a:27017")
+	assert.Equal(t, 1.0, values["mongodb_mongod_replset_member_sync_source_info/dc2-a:27017"])
+	assert.NotContains(t, values, "mongodb_mongod_replset_member_sync_source_info/dc3-a:27017")
+	assert.Equal(t, 3.0, values["mongodb_mongod_replset_member_sync_edge/dc2-a:27017"])
+	assert.Equal(t, 5.0, values["mongodb_mongod_replset_member_sync_edge/dc2-b:27017"])
+}
+
+func TestReplSetStatusEffectiveReplicationLag(t *testing.T) {
+	now := time.Unix(1561982400, 0)
+	status := &ReplSetStatus{Set: "rs0", Members: []Member{
+		{Name: "rs1:27017", StateStr: "PRIMARY", OptimeDate: now},
+		{Name: "rs2:27017", StateStr: "SECONDARY", OptimeDate: now.Add(-10 * time.Second)},
+		{Name: "rs3:27017", StateStr: "SECONDARY", OptimeDate: now.Add(-3610 * time.Second)},
+		{Name: "rs4:27017", StateStr: "SECONDARY", OptimeDate: now.Add(-3590 * time.Second)},
+	}}
+	delay := 3600.0
+	conf := &ReplSetConf{ID: "rs0", Members: []MemberConf{
+		{Host: "rs1:27017"},
+		{Host: "rs2:27017"},
+		{Host: "rs3:27017", SlaveDelay: delay},
+		{Host: "rs4:27017", SecondaryDelaySecs: &delay},
+	}}
+
+	lags := testutils.CollectMetrics(func(ch chan<- prometheus.Metric) {
+		status.ExportEffectiveReplicationLag(ch, conf)
+	}, "name")
+
+	assert.Equal(t, map[string]float64{
+		"mongodb_mongod_replset_member_effective_replication_lag/rs2:27017": 10,
+		"mongodb_mongod_replset_member_effective_replication_lag/rs3:27017": 10,
+		"mongodb_mongod_replset_member_effective_replication_lag/rs4:27017": 0,
+	}, lags)
+}
@@ -0,0 +1,139 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// rollbackState is the ROLLBACK member state.
+const rollbackState = 9
+
+var (
+	rollbackIDDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "rollback_id"),
+		"The rollback id (rbid) of this member, which changes on every rollback.",
+		[]string{"set"},
+		nil,
+	)
+	rollbackStateDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, subsystem, "rollback_state_duration_seconds"),
+		"The time this member has been in the ROLLBACK state, 0 if not rolling back.",
+		[]string{"set"},
+		nil,
+	)
+)
+
+// rollbackObservation is the last state observed for a replica set.
+type rollbackObservation struct {
+	rbid  *int32
+	since time.Time // when the ROLLBACK state was first observed, zero if not rolling back
+	now   time.Time
+}
+
+// RollbackTracker counts the rollbacks of this member from the rbid changes observed between scrapes
+// and how long the member stays in the ROLLBACK state.
+type RollbackTracker struct {
+	m            sync.Mutex
+	observations map[string]*rollbackObservation
+	rollbacks    *prometheus.CounterVec
+}
+
+// NewRollbackTracker creates a new RollbackTracker.
+func NewRollbackTracker() *RollbackTracker {
+	return &RollbackTracker{
+		observations: make(map[string]*rollbackObservation),
+		rollbacks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: subsystem,
+			Name:      "rollbacks_total",
+			Help:      "The total number of rollbacks of this member observed by the exporter from rbid changes",
+		}, []string{"set"}),
+	}
+}
+
+// Observe records the member state and rollback id at the given time.
+func (tracker *RollbackTracker) Observe(status *ReplSetStatus, rbid *int32, now time.Time) {
+	tracker.m.Lock()
+	defer tracker.m.Unlock()
+
+	// initialize the series on the first observation
+	counter := tracker.rollbacks.WithLabelValues(status.Set)
+	last, seen := tracker.observations[status.Set]
+	if !seen {
+		last = &rollbackObservation{}
+		tracker.observations[status.Set] = last
+	}
+
+	if rbid != nil {
+		if last.rbid != nil && *last.rbid != *rbid {
+			counter.Inc()
+		}
+		last.rbid = rbid
+	}
+
+	switch {
+	case status.MyState != rollbackState:
+		last.since = time.Time{}
+	case last.since.IsZero():
+		last.since = now
+	}
+	last.now = now
+}
+
+// Export exports the rollback metrics.
+func (tracker *RollbackTracker) Export(ch chan<- prometheus.Metric) {
+	tracker.m.Lock()
+	defer tracker.m.Unlock()
+
+	for set, last := range tracker.observations {
+		if last.rbid != nil {
+			ch <- prometheus.MustNewConstMetric(rollbackIDDesc, prometheus.GaugeValue, float64(*last.rbid), set)
+		}
+		duration := 0.0
+		if !last.since.IsZero() {
+			duration = last.now.Sub(last.since).Seconds()
+		}
+		ch <- prometheus.MustNewConstMetric(rollbackStateDurationDesc, prometheus.GaugeValue, duration, set)
+	}
+	tracker.rollbacks.Collect(ch)
+}
+
+// Describe describes the rollback metrics for prometheus.
+func (tracker *RollbackTracker) Describe(ch chan<- *prometheus.Desc) {
+	ch <- rollbackIDDesc
+	ch <- rollbackStateDurationDesc
+	tracker.rollbacks.Describe(ch)
+}
+
+// GetRollbackID returns the rollback id of the member, nil if it can't be read.
+func GetRollbackID(client *mongo.Client) *int32 {
+	result := struct {
+		RBID int32 `bson:"rbid"`
+	}{}
+	err := client.Database("admin").RunCommand(context.TODO(), bson.D{{"replSetGetRBID", 1}}).Decode(&result)
+	if err != nil {
+		log.Errorf("Failed to get replSetGetRBID: %s.", err)
+		return nil
+	}
+	return &result.RBID
+}
@@ -0,0 +1,55 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/percona/mongodb_exporter/testutils"
+)
+
+func TestRollbackTracker(t *testing.T) {
+	export := func(tracker *RollbackTracker) map[string]float64 {
+		return testutils.CollectMetrics(tracker.Export)
+	}
+	rbid := func(id int32) *int32 { return &id }
+	now := time.Unix(1561982400, 0)
+
+	tracker := NewRollbackTracker()
+
+	// the first observation initializes the counter only
+	tracker.Observe(&ReplSetStatus{Set: "rs0", MyState: 2}, rbid(3), now)
+	values := export(tracker)
+	assert.Equal(t, 0.0, values["mongodb_mongod_replset_rollbacks_total"])
+	assert.Equal(t, 3.0, values["mongodb_mongod_replset_rollback_id"])
+	assert.Equal(t, 0.0, values["mongodb_mongod_replset_rollback_state_duration_seconds"])
+
+	// rolling back
+	tracker.Observe(&ReplSetStatus{Set: "rs0", MyState: rollbackState}, nil, now.Add(15*time.Second))
+	tracker.Observe(&ReplSetStatus{Set: "rs0", MyState: rollbackState}, rbid(4), now.Add(45*time.Second))
+	values = export(tracker)
+	assert.Equal(t, 1.0, values["mongodb_mongod_replset_rollbacks_total"])
+	assert.Equal(t, 4.0, values["mongodb_mongod_replset_rollback_id"])
+	assert.Equal(t, 30.0, values["mongodb_mongod_replset_rollback_state_duration_seconds"])
+
+	// back to secondary
+	tracker.Observe(&ReplSetStatus{Set: "rs0", MyState: 2}, rbid(4), now.Add(60*time.Second))
+	values = export(tracker)
+	assert.Equal(t, 1.0, values["mongodb_mongod_replset_rollbacks_total"])
+	assert.Equal(t, 0.0, values["mongodb_mongod_replset_rollback_state_duration_seconds"])
+}
@@ -47,6 +47,14 @@ type ServerStatus struct {
 	RocksDb       *RocksDbStats       `bson:"rocksdb"`
 	WiredTiger    *WiredTigerStats    `bson:"wiredTiger"`
 
+	Transactions              *TransactionsStats              `bson:"transactions"`
+	TwoPhaseCommitCoordinator *TwoPhaseCommitCoordinatorStats `bson:"twoPhaseCommitCoordinator"`
+
+	ElectionMetrics *ElectionMetricsStats `bson:"electionMetrics"`
+	IndexBuilds     *IndexBuildsStats     `bson:"indexBuilds"`
+
+	ShardingStatistics *ShardingStatisticsStats `bson:"shardingStatistics"`
+
 	Ok float64 `bson:"ok"`
 }
 
@@ -83,6 +91,21 @@ func (status *ServerStatus) Export(ch chan<- prometheus.Metric) {
 	if status.WiredTiger != nil {
 		status.WiredTiger.Export(ch)
 	}
+	if status.Transactions != nil {
+		status.Transactions.Export(ch)
+	}
+	if status.TwoPhaseCommitCoordinator != nil {
+		status.TwoPhaseCommitCoordinator.Export(ch)
+	}
+	if status.ElectionMetrics != nil {
+		status.ElectionMetrics.Export(ch)
+	}
+	if status.IndexBuilds != nil {
+		status.IndexBuilds.Export(ch)
+	}
+	if status.ShardingStatistics != nil {
+		status.ShardingStatistics.Export(ch)
+	}
 	// If db.serverStatus().storageEngine does not exist (3.0+ only) and status.BackgroundFlushing does (MMAPv1 only), default to mmapv1
 	// https://docs.mongodb.com/v3.0/reference/command/serverStatus/#storageengine
 	if status.StorageEngine == nil && status.BackgroundFlushing != nil {
@@ -137,6 +160,29 @@ func (status *ServerStatus) Describe(ch chan<- *prometheus.Desc) {
 	if status.WiredTiger != nil {
 		status.WiredTiger.Describe(ch)
 	}
+	if status.Transactions != nil {
+		status.Transactions.Describe(ch)
+	}
+	if status.TwoPhaseCommitCoordinator != nil {
+		status.TwoPhaseCommitCoordinator.Describe(ch)
+	}
+	if status.ElectionMetrics != nil {
+		status.ElectionMetrics.Describe(ch)
+	}
+	if status.IndexBuilds != nil {
+		status.IndexBuilds.Describe(ch)
+	}
+	if status.ShardingStatistics != nil {
+		status.ShardingStatistics.Describe(ch)
+	}
+}
+
+// FilterCommands keeps only the metrics.commands counters of the commands in allowlist.
+// An empty allowlist keeps all commands.
+func (status *ServerStatus) FilterCommands(allowlist []string) {
+	if status.Metrics != nil && status.Metrics.Commands != nil {
+		status.Metrics.Commands.Filter(allowlist)
+	}
 }
 
 // GetServerStatus returns the server status info.
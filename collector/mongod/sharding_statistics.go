@@ -0,0 +1,230 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	shardingStaleConfigErrorsTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "stale_config_errors_total"),
+		"The total number of times a thread hit a stale config exception",
+		nil,
+		nil,
+	)
+	shardingDonorMoveChunkTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "donor_move_chunk_total"),
+		"The total number of chunk migrations started, committed, aborted, or that timed out acquiring the collection lock, with this shard as the donor",
+		[]string{"result"},
+		nil,
+	)
+	shardingRecipientMoveChunkStartedTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "recipient_move_chunk_started_total"),
+		"The total number of chunk migrations started with this shard as the recipient",
+		nil,
+		nil,
+	)
+	shardingMoveChunkTimeTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "move_chunk_time_seconds_total"),
+		"The total time spent by chunk migrations from this shard, cloning chunks and in the critical section",
+		[]string{"type"},
+		nil,
+	)
+	shardingDocsClonedTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "docs_cloned_total"),
+		"The total number of documents cloned by chunk migrations on this shard as the donor or the recipient",
+		[]string{"side"},
+		nil,
+	)
+	shardingBytesClonedTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "bytes_cloned_total"),
+		"The total number of bytes cloned by chunk migrations on this shard as the recipient",
+		[]string{"side"},
+		nil,
+	)
+	shardingDocsDeletedTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "range_deleter_docs_deleted_total"),
+		"The total number of documents deleted by the range deleter after chunk migrations",
+		nil,
+		nil,
+	)
+	shardingRangeDeleterTasksDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "range_deleter_tasks"),
+		"The number of range deletion tasks queued or running",
+		nil,
+		nil,
+	)
+	shardingUnfinishedMigrationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "unfinished_migration_from_previous_primary"),
+		"The number of unfinished chunk migrations left by the previous primary and recovered after an election",
+		nil,
+		nil,
+	)
+	shardingCatalogCacheEntriesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "catalog_cache_entries"),
+		"The number of databases and collections in the routing table cache",
+		[]string{"type"},
+		nil,
+	)
+	shardingCatalogCacheStaleConfigErrorsTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "catalog_cache_stale_config_errors_total"),
+		"The total number of times a thread hit a stale config exception that triggered a routing table refresh",
+		nil,
+		nil,
+	)
+	shardingCatalogCacheRefreshWaitTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "catalog_cache_refresh_wait_seconds_total"),
+		"The total time threads waited for routing table refreshes",
+		nil,
+		nil,
+	)
+	shardingCatalogCacheActiveRefreshesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "catalog_cache_active_refreshes"),
+		"The number of incremental and full routing table refreshes waiting to complete",
+		[]string{"type"},
+		nil,
+	)
+	shardingCatalogCacheRefreshesTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "catalog_cache_refreshes_total"),
+		"The total number of incremental and full routing table refreshes started",
+		[]string{"type"},
+		nil,
+	)
+	shardingCatalogCacheFailedRefreshesTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "catalog_cache_failed_refreshes_total"),
+		"The total number of routing table refreshes that failed",
+		nil,
+		nil,
+	)
+)
+
+// CatalogCacheStats are the shardingStatistics catalogCache (4.0+).
+type CatalogCacheStats struct {
+	NumDatabaseEntries               float64 `bson:"numDatabaseEntries"`
+	NumCollectionEntries             float64 `bson:"numCollectionEntries"`
+	CountStaleConfigErrors           float64 `bson:"countStaleConfigErrors"`
+	TotalRefreshWaitTimeMicros       float64 `bson:"totalRefreshWaitTimeMicros"`
+	NumActiveIncrementalRefreshes    float64 `bson:"numActiveIncrementalRefreshes"`
+	CountIncrementalRefreshesStarted float64 `bson:"countIncrementalRefreshesStarted"`
+	NumActiveFullRefreshes           float64 `bson:"numActiveFullRefreshes"`
+	CountFullRefreshesStarted        float64 `bson:"countFullRefreshesStarted"`
+	CountFailedRefreshes             float64 `bson:"countFailedRefreshes"`
+}
+
+// Export exports the catalog cache stats.
+func (stats *CatalogCacheStats) Export(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(shardingCatalogCacheEntriesDesc, prometheus.GaugeValue, stats.NumDatabaseEntries, "database")
+	ch <- prometheus.MustNewConstMetric(shardingCatalogCacheEntriesDesc, prometheus.GaugeValue, stats.NumCollectionEntries, "collection")
+	ch <- prometheus.MustNewConstMetric(shardingCatalogCacheStaleConfigErrorsTotalDesc, prometheus.CounterValue, stats.CountStaleConfigErrors)
+	ch <- prometheus.MustNewConstMetric(shardingCatalogCacheRefreshWaitTotalDesc, prometheus.CounterValue, stats.TotalRefreshWaitTimeMicros/1e6)
+	ch <- prometheus.MustNewConstMetric(shardingCatalogCacheActiveRefreshesDesc, prometheus.GaugeValue, stats.NumActiveIncrementalRefreshes, "incremental")
+	ch <- prometheus.MustNewConstMetric(shardingCatalogCacheActiveRefreshesDesc, prometheus.GaugeValue, stats.NumActiveFullRefreshes, "full")
+	ch <- prometheus.MustNewConstMetric(shardingCatalogCacheRefreshesTotalDesc, prometheus.CounterValue, stats.CountIncrementalRefreshesStarted, "incremental")
+	ch <- prometheus.MustNewConstMetric(shardingCatalogCacheRefreshesTotalDesc, prometheus.CounterValue, stats.CountFullRefreshesStarted, "full")
+	ch <- prometheus.MustNewConstMetric(shardingCatalogCacheFailedRefreshesTotalDesc, prometheus.CounterValue, stats.CountFailedRefreshes)
+}
+
+// ShardingStatisticsStats are the serverStatus shardingStatistics of a shard member (3.6+).
+type ShardingStatisticsStats struct {
+	CountStaleConfigErrors               float64 `bson:"countStaleConfigErrors"`
+	CountDonorMoveChunkStarted           float64 `bson:"countDonorMoveChunkStarted"`
+	TotalDonorChunkCloneTimeMillis       float64 `bson:"totalDonorChunkCloneTimeMillis"`
+	TotalCriticalSectionCommitTimeMillis float64 `bson:"totalCriticalSectionCommitTimeMillis"`
+	TotalCriticalSectionTimeMillis       float64 `bson:"totalCriticalSectionTimeMillis"`
+	CountDocsClonedOnRecipient           float64 `bson:"countDocsClonedOnRecipient"`
+	CountDocsClonedOnDonor               float64 `bson:"countDocsClonedOnDonor"`
+	CountRecipientMoveChunkStarted       float64 `bson:"countRecipientMoveChunkStarted"`
+
+	// new in version 4.4 and later, or renamed
+	CountDonorMoveChunkCommitted           *float64           `bson:"countDonorMoveChunkCommitted,omitempty"`
+	CountDonorMoveChunkAborted             *float64           `bson:"countDonorMoveChunkAborted,omitempty"`
+	CountDonorMoveChunkLockTimeout         *float64           `bson:"countDonorMoveChunkLockTimeout,omitempty"`
+	TotalDonorMoveChunkTimeMillis          *float64           `bson:"totalDonorMoveChunkTimeMillis,omitempty"`
+	CountBytesClonedOnRecipient            *float64           `bson:"countBytesClonedOnRecipient,omitempty"`
+	CountDocsDeletedOnDonor                *float64           `bson:"countDocsDeletedOnDonor,omitempty"`
+	CountDocsDeletedByRangeDeleter         *float64           `bson:"countDocsDeletedByRangeDeleter,omitempty"`
+	RangeDeleterTasks                      *float64           `bson:"rangeDeleterTasks,omitempty"`
+	UnfinishedMigrationFromPreviousPrimary *float64           `bson:"unfinishedMigrationFromPreviousPrimary,omitempty"`
+	CatalogCache                           *CatalogCacheStats `bson:"catalogCache,omitempty"`
+}
+
+// Export exports the sharding statistics.
+func (stats *ShardingStatisticsStats) Export(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(shardingStaleConfigErrorsTotalDesc, prometheus.CounterValue, stats.CountStaleConfigErrors)
+
+	ch <- prometheus.MustNewConstMetric(shardingDonorMoveChunkTotalDesc, prometheus.CounterValue, stats.CountDonorMoveChunkStarted, "started")
+	for result, val := range map[string]*float64{
+		"committed":    stats.CountDonorMoveChunkCommitted,
+		"aborted":      stats.CountDonorMoveChunkAborted,
+		"lock_timeout": stats.CountDonorMoveChunkLockTimeout,
+	} {
+		if val != nil {
+			ch <- prometheus.MustNewConstMetric(shardingDonorMoveChunkTotalDesc, prometheus.CounterValue, *val, result)
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(shardingRecipientMoveChunkStartedTotalDesc, prometheus.CounterValue, stats.CountRecipientMoveChunkStarted)
+
+	ch <- prometheus.MustNewConstMetric(shardingMoveChunkTimeTotalDesc, prometheus.CounterValue, stats.TotalDonorChunkCloneTimeMillis/1000, "clone")
+	ch <- prometheus.MustNewConstMetric(shardingMoveChunkTimeTotalDesc, prometheus.CounterValue, stats.TotalCriticalSectionTimeMillis/1000, "critical_section")
+	ch <- prometheus.MustNewConstMetric(shardingMoveChunkTimeTotalDesc, prometheus.CounterValue, stats.TotalCriticalSectionCommitTimeMillis/1000, "critical_section_commit")
+	if stats.TotalDonorMoveChunkTimeMillis != nil {
+		ch <- prometheus.MustNewConstMetric(shardingMoveChunkTimeTotalDesc, prometheus.CounterValue, *stats.TotalDonorMoveChunkTimeMillis/1000, "total")
+	}
+
+	ch <- prometheus.MustNewConstMetric(shardingDocsClonedTotalDesc, prometheus.CounterValue, stats.CountDocsClonedOnDonor, "donor")
+	ch <- prometheus.MustNewConstMetric(shardingDocsClonedTotalDesc, prometheus.CounterValue, stats.CountDocsClonedOnRecipient, "recipient")
+	if stats.CountBytesClonedOnRecipient != nil {
+		ch <- prometheus.MustNewConstMetric(shardingBytesClonedTotalDesc, prometheus.CounterValue, *stats.CountBytesClonedOnRecipient, "recipient")
+	}
+
+	// countDocsDeletedOnDonor was renamed countDocsDeletedByRangeDeleter in version 5.0
+	deleted := stats.CountDocsDeletedByRangeDeleter
+	if deleted == nil {
+		deleted = stats.CountDocsDeletedOnDonor
+	}
+	if deleted != nil {
+		ch <- prometheus.MustNewConstMetric(shardingDocsDeletedTotalDesc, prometheus.CounterValue, *deleted)
+	}
+	if stats.RangeDeleterTasks != nil {
+		ch <- prometheus.MustNewConstMetric(shardingRangeDeleterTasksDesc, prometheus.GaugeValue, *stats.RangeDeleterTasks)
+	}
+	if stats.UnfinishedMigrationFromPreviousPrimary != nil {
+		ch <- prometheus.MustNewConstMetric(shardingUnfinishedMigrationDesc, prometheus.GaugeValue, *stats.UnfinishedMigrationFromPreviousPrimary)
+	}
+
+	if stats.CatalogCache != nil {
+		stats.CatalogCache.Export(ch)
+	}
+}
+
+// Describe describes the sharding statistics for prometheus.
+func (stats *ShardingStatisticsStats) Describe(ch chan<- *prometheus.Desc) {
+	ch <- shardingStaleConfigErrorsTotalDesc
+	ch <- shardingDonorMoveChunkTotalDesc
+	ch <- shardingRecipientMoveChunkStartedTotalDesc
+	ch <- shardingMoveChunkTimeTotalDesc
+	ch <- shardingDocsClonedTotalDesc
+	ch <- shardingBytesClonedTotalDesc
+	ch <- shardingDocsDeletedTotalDesc
+	ch <- shardingRangeDeleterTasksDesc
+	ch <- shardingUnfinishedMigrationDesc
+	ch <- shardingCatalogCacheEntriesDesc
+	ch <- shardingCatalogCacheStaleConfigErrorsTotalDesc
+	ch <- shardingCatalogCacheRefreshWaitTotalDesc
+	ch <- shardingCatalogCacheActiveRefreshesDesc
+	ch <- shardingCatalogCacheRefreshesTotalDesc
+	ch <- shardingCatalogCacheFailedRefreshesTotalDesc
+}
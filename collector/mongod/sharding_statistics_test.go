@@ -0,0 +1,82 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/percona/mongodb_exporter/testutils"
+)
+
+func TestShardingStatisticsExport(t *testing.T) {
+	status := &ServerStatus{}
+	testutils.MustDecodeBSON(t, bson.M{
+		"shardingStatistics": bson.M{
+			"countStaleConfigErrors":               int64(3),
+			"countDonorMoveChunkStarted":           int64(10),
+			"countDonorMoveChunkCommitted":         int64(8),
+			"countDonorMoveChunkAborted":           int64(2),
+			"totalDonorMoveChunkTimeMillis":        int64(5000),
+			"totalDonorChunkCloneTimeMillis":       int64(3000),
+			"totalCriticalSectionCommitTimeMillis": int64(200),
+			"totalCriticalSectionTimeMillis":       int64(700),
+			"countDocsClonedOnRecipient":           int64(1000),
+			"countBytesClonedOnRecipient":          int64(64000),
+			"countDocsClonedOnDonor":               int64(2000),
+			"countRecipientMoveChunkStarted":       int64(5),
+			"countDocsDeletedOnDonor":              int64(1500),
+			"countDocsDeletedByRangeDeleter":       int64(1800),
+			"rangeDeleterTasks":                    int64(1),
+			"catalogCache": bson.M{
+				"numDatabaseEntries":               int64(4),
+				"numCollectionEntries":             int64(12),
+				"countStaleConfigErrors":           int64(6),
+				"totalRefreshWaitTimeMicros":       int64(2500000),
+				"numActiveIncrementalRefreshes":    int64(0),
+				"countIncrementalRefreshesStarted": int64(40),
+				"numActiveFullRefreshes":           int64(1),
+				"countFullRefreshesStarted":        int64(9),
+				"countFailedRefreshes":             int64(2),
+			},
+		},
+	}, status)
+	require.NotNil(t, status.ShardingStatistics)
+
+	values := testutils.CollectMetrics(status.ShardingStatistics.Export, "result", "type", "side")
+
+	assert.Equal(t, 3.0, values["mongodb_mongod_sharding_stale_config_errors_total"])
+	assert.Equal(t, 10.0, values["mongodb_mongod_sharding_donor_move_chunk_total/started"])
+	assert.Equal(t, 8.0, values["mongodb_mongod_sharding_donor_move_chunk_total/committed"])
+	assert.Equal(t, 2.0, values["mongodb_mongod_sharding_donor_move_chunk_total/aborted"])
+	assert.NotContains(t, values, "mongodb_mongod_sharding_donor_move_chunk_total/lock_timeout")
+	assert.Equal(t, 5.0, values["mongodb_mongod_sharding_recipient_move_chunk_started_total"])
+	assert.Equal(t, 5.0, values["mongodb_mongod_sharding_move_chunk_time_seconds_total/total"])
+	assert.Equal(t, 3.0, values["mongodb_mongod_sharding_move_chunk_time_seconds_total/clone"])
+	assert.Equal(t, 0.7, values["mongodb_mongod_sharding_move_chunk_time_seconds_total/critical_section"])
+	assert.Equal(t, 2000.0, values["mongodb_mongod_sharding_docs_cloned_total/donor"])
+	assert.Equal(t, 1000.0, values["mongodb_mongod_sharding_docs_cloned_total/recipient"])
+	assert.Equal(t, 64000.0, values["mongodb_mongod_sharding_bytes_cloned_total/recipient"])
+	assert.Equal(t, 1800.0, values["mongodb_mongod_sharding_range_deleter_docs_deleted_total"])
+	assert.Equal(t, 1.0, values["mongodb_mongod_sharding_range_deleter_tasks"])
+	assert.Equal(t, 12.0, values["mongodb_mongod_sharding_catalog_cache_entries/collection"])
+	assert.Equal(t, 2.5, values["mongodb_mongod_sharding_catalog_cache_refresh_wait_seconds_total"])
+	assert.Equal(t, 1.0, values["mongodb_mongod_sharding_catalog_cache_active_refreshes/full"])
+	assert.Equal(t, 40.0, values["mongodb_mongod_sharding_catalog_cache_refreshes_total/incremental"])
+	assert.Equal(t, 2.0, values["mongodb_mongod_sharding_catalog_cache_failed_refreshes_total"])
+}
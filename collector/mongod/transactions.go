@@ -0,0 +1,184 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	transactionsCurrentDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "transactions", "current"),
+		"The current number of active, inactive, open and prepared transactions",
+		[]string{"state"},
+		nil,
+	)
+	transactionsTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "transactions", "total"),
+		"The total number of transactions started, committed, aborted and prepared since the mongod process was last started",
+		[]string{"type"},
+		nil,
+	)
+	transactionsRetriedCommandsTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "transactions", "retried_commands_total"),
+		"retriedCommandsCount reports the total number of retry attempts received for retryable write commands that have already been committed",
+		nil,
+		nil,
+	)
+	transactionsRetriedStatementsTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "transactions", "retried_statements_total"),
+		"retriedStatementsCount reports the total number of write statements associated with the retried commands",
+		nil,
+		nil,
+	)
+	transactionsCollectionWriteTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "transactions", "collection_write_total"),
+		"transactionsCollectionWriteCount reports the total number of writes to the config.transactions collection",
+		nil,
+		nil,
+	)
+	transactionsCommitTypesTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "transactions", "commit_types_total"),
+		"The total number of initiated and successful transaction commits by commit type",
+		[]string{"type", "state"},
+		nil,
+	)
+	transactionsCommitTypesSuccessfulDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "transactions", "commit_types_successful_duration_microseconds_total"),
+		"The total duration in microseconds of the successful transaction commits by commit type",
+		[]string{"type"},
+		nil,
+	)
+)
+
+var (
+	twoPhaseCommitCoordinatorTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "two_phase_commit_coordinator", "total"),
+		"The total number of two-phase commit coordinators created, started, aborted and committed on this shard",
+		[]string{"type"},
+		nil,
+	)
+	twoPhaseCommitCoordinatorCurrentInStepsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "two_phase_commit_coordinator", "current_in_steps"),
+		"The current number of two-phase commit coordinators in each step of the commit",
+		[]string{"step"},
+		nil,
+	)
+)
+
+// TransactionCommitTypeStats are the counters of a single transaction commit type.
+type TransactionCommitTypeStats struct {
+	Initiated                float64 `bson:"initiated"`
+	Successful               float64 `bson:"successful"`
+	SuccessfulDurationMicros float64 `bson:"successfulDurationMicros"`
+}
+
+// TransactionsStats are the retryable writes and multi-document transactions stats (4.0+).
+type TransactionsStats struct {
+	RetriedCommandsCount             float64 `bson:"retriedCommandsCount"`
+	RetriedStatementsCount           float64 `bson:"retriedStatementsCount"`
+	TransactionsCollectionWriteCount float64 `bson:"transactionsCollectionWriteCount"`
+
+	// new in version 4.0.2
+	CurrentActive   *float64 `bson:"currentActive"`
+	CurrentInactive *float64 `bson:"currentInactive"`
+	CurrentOpen     *float64 `bson:"currentOpen"`
+	TotalAborted    *float64 `bson:"totalAborted"`
+	TotalCommitted  *float64 `bson:"totalCommitted"`
+	TotalStarted    *float64 `bson:"totalStarted"`
+
+	// new in version 4.2
+	CurrentPrepared            *float64 `bson:"currentPrepared"`
+	TotalPrepared              *float64 `bson:"totalPrepared"`
+	TotalPreparedThenCommitted *float64 `bson:"totalPreparedThenCommitted"`
+	TotalPreparedThenAborted   *float64 `bson:"totalPreparedThenAborted"`
+
+	CommitTypes map[string]TransactionCommitTypeStats `bson:"commitTypes"`
+}
+
+// Export exports the transactions stats.
+func (stats *TransactionsStats) Export(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(transactionsRetriedCommandsTotalDesc, prometheus.CounterValue, stats.RetriedCommandsCount)
+	ch <- prometheus.MustNewConstMetric(transactionsRetriedStatementsTotalDesc, prometheus.CounterValue, stats.RetriedStatementsCount)
+	ch <- prometheus.MustNewConstMetric(transactionsCollectionWriteTotalDesc, prometheus.CounterValue, stats.TransactionsCollectionWriteCount)
+
+	for state, val := range map[string]*float64{
+		"active":   stats.CurrentActive,
+		"inactive": stats.CurrentInactive,
+		"open":     stats.CurrentOpen,
+		"prepared": stats.CurrentPrepared,
+	} {
+		if val != nil {
+			ch <- prometheus.MustNewConstMetric(transactionsCurrentDesc, prometheus.GaugeValue, *val, state)
+		}
+	}
+
+	for typ, val := range map[string]*float64{
+		"started":                 stats.TotalStarted,
+		"committed":               stats.TotalCommitted,
+		"aborted":                 stats.TotalAborted,
+		"prepared":                stats.TotalPrepared,
+		"prepared_then_committed": stats.TotalPreparedThenCommitted,
+		"prepared_then_aborted":   stats.TotalPreparedThenAborted,
+	} {
+		if val != nil {
+			ch <- prometheus.MustNewConstMetric(transactionsTotalDesc, prometheus.CounterValue, *val, typ)
+		}
+	}
+
+	for commitType, commitStats := range stats.CommitTypes {
+		ch <- prometheus.MustNewConstMetric(transactionsCommitTypesTotalDesc, prometheus.CounterValue, commitStats.Initiated, commitType, "initiated")
+		ch <- prometheus.MustNewConstMetric(transactionsCommitTypesTotalDesc, prometheus.CounterValue, commitStats.Successful, commitType, "successful")
+		ch <- prometheus.MustNewConstMetric(transactionsCommitTypesSuccessfulDurationDesc, prometheus.CounterValue, commitStats.SuccessfulDurationMicros, commitType)
+	}
+}
+
+// Describe describes the transactions stats for prometheus.
+func (stats *TransactionsStats) Describe(ch chan<- *prometheus.Desc) {
+	ch <- transactionsCurrentDesc
+	ch <- transactionsTotalDesc
+	ch <- transactionsRetriedCommandsTotalDesc
+	ch <- transactionsRetriedStatementsTotalDesc
+	ch <- transactionsCollectionWriteTotalDesc
+	ch <- transactionsCommitTypesTotalDesc
+	ch <- transactionsCommitTypesSuccessfulDurationDesc
+}
+
+// TwoPhaseCommitCoordinatorStats are the two-phase commit coordinator stats of a shard (4.2+).
+type TwoPhaseCommitCoordinatorStats struct {
+	TotalCreated                 float64            `bson:"totalCreated"`
+	TotalStartedTwoPhaseCommit   float64            `bson:"totalStartedTwoPhaseCommit"`
+	TotalAbortedTwoPhaseCommit   float64            `bson:"totalAbortedTwoPhaseCommit"`
+	TotalCommittedTwoPhaseCommit float64            `bson:"totalCommittedTwoPhaseCommit"`
+	CurrentInSteps               map[string]float64 `bson:"currentInSteps"`
+}
+
+// Export exports the two-phase commit coordinator stats.
+func (stats *TwoPhaseCommitCoordinatorStats) Export(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(twoPhaseCommitCoordinatorTotalDesc, prometheus.CounterValue, stats.TotalCreated, "created")
+	ch <- prometheus.MustNewConstMetric(twoPhaseCommitCoordinatorTotalDesc, prometheus.CounterValue, stats.TotalStartedTwoPhaseCommit, "started")
+	ch <- prometheus.MustNewConstMetric(twoPhaseCommitCoordinatorTotalDesc, prometheus.CounterValue, stats.TotalAbortedTwoPhaseCommit, "aborted")
+	ch <- prometheus.MustNewConstMetric(twoPhaseCommitCoordinatorTotalDesc, prometheus.CounterValue, stats.TotalCommittedTwoPhaseCommit, "committed")
+
+	for step, val := range stats.CurrentInSteps {
+		ch <- prometheus.MustNewConstMetric(twoPhaseCommitCoordinatorCurrentInStepsDesc, prometheus.GaugeValue, val, step)
+	}
+}
+
+// Describe describes the two-phase commit coordinator stats for prometheus.
+func (stats *TwoPhaseCommitCoordinatorStats) Describe(ch chan<- *prometheus.Desc) {
+	ch <- twoPhaseCommitCoordinatorTotalDesc
+	ch <- twoPhaseCommitCoordinatorCurrentInStepsDesc
+}
@@ -0,0 +1,75 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/percona/mongodb_exporter/testutils"
+)
+
+func TestTransactionsStatsExport(t *testing.T) {
+	status := &ServerStatus{}
+	testutils.MustDecodeBSON(t, bson.M{
+		"transactions": bson.M{
+			"retriedCommandsCount":             int64(3),
+			"retriedStatementsCount":           int64(4),
+			"transactionsCollectionWriteCount": int64(5),
+			"currentActive":                    int64(1),
+			"currentInactive":                  int64(2),
+			"currentOpen":                      int64(3),
+			"totalAborted":                     int64(10),
+			"totalCommitted":                   int64(90),
+			"totalStarted":                     int64(100),
+		},
+		"twoPhaseCommitCoordinator": bson.M{
+			"totalCreated":                 int64(7),
+			"totalStartedTwoPhaseCommit":   int64(6),
+			"totalAbortedTwoPhaseCommit":   int64(1),
+			"totalCommittedTwoPhaseCommit": int64(5),
+			"currentInSteps": bson.M{
+				"writingParticipantList": int64(0),
+				"waitingForVotes":        int64(1),
+			},
+		},
+	}, status)
+
+	values := testutils.CollectMetrics(func(ch chan<- prometheus.Metric) {
+		status.Transactions.Export(ch)
+		status.TwoPhaseCommitCoordinator.Export(ch)
+	}, "state", "type", "step")
+
+	assert.Equal(t, map[string]float64{
+		"mongodb_mongod_transactions_retried_commands_total":                                  3,
+		"mongodb_mongod_transactions_retried_statements_total":                                4,
+		"mongodb_mongod_transactions_collection_write_total":                                  5,
+		"mongodb_mongod_transactions_current/active":                                          1,
+		"mongodb_mongod_transactions_current/inactive":                                        2,
+		"mongodb_mongod_transactions_current/open":                                            3,
+		"mongodb_mongod_transactions_total/aborted":                                           10,
+		"mongodb_mongod_transactions_total/committed":                                         90,
+		"mongodb_mongod_transactions_total/started":                                           100,
+		"mongodb_mongod_two_phase_commit_coordinator_total/created":                           7,
+		"mongodb_mongod_two_phase_commit_coordinator_total/started":                           6,
+		"mongodb_mongod_two_phase_commit_coordinator_total/aborted":                           1,
+		"mongodb_mongod_two_phase_commit_coordinator_total/committed":                         5,
+		"mongodb_mongod_two_phase_commit_coordinator_current_in_steps/writingParticipantList": 0,
+		"mongodb_mongod_two_phase_commit_coordinator_current_in_steps/waitingForVotes":        1,
+	}, values)
+}
@@ -0,0 +1,166 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/percona/mongodb_exporter/shared"
+)
+
+var (
+	ttlLagDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "", "index_ttl_lag_seconds"),
+		"The number of seconds the oldest document of the TTL index is overdue for deletion",
+		[]string{"db", "coll", "index"},
+		nil,
+	)
+)
+
+// TTLLagStats represents the oldest indexed date of a TTL index
+type TTLLagStats struct {
+	Index IndexInfo
+	// Oldest is the oldest date in the index, zero if the index has no date.
+	Oldest time.Time
+}
+
+// TTLLagStatList contains the lag of all TTL indexes
+type TTLLagStatList struct {
+	Items []TTLLagStats
+	Now   time.Time
+}
+
+// Lag returns the seconds between the expiration of the oldest document and now, 0 if it is not expired yet.
+func (stats *TTLLagStats) Lag(now time.Time) float64 {
+	expireAfterSeconds, _ := stats.Index.TTL()
+	if stats.Oldest.IsZero() {
+		return 0
+	}
+	lag := now.Sub(stats.Oldest).Seconds() - expireAfterSeconds
+	if lag < 0 {
+		return 0
+	}
+	return lag
+}
+
+// Export exports TTL index lags to prometheus
+func (ttlLagList *TTLLagStatList) Export(ch chan<- prometheus.Metric) {
+	for _, item := range ttlLagList.Items {
+		ch <- prometheus.MustNewConstMetric(ttlLagDesc, prometheus.GaugeValue, item.Lag(ttlLagList.Now), item.Index.Database, item.Index.Collection, item.Index.Name)
+	}
+}
+
+// Describe describes TTL index lags for prometheus
+func (ttlLagList *TTLLagStatList) Describe(ch chan<- *prometheus.Desc) {
+	ch <- ttlLagDesc
+}
+
+// getOldestIndexedDate returns the oldest date of a single-field TTL index with a covered index-only query.
+func getOldestIndexedDate(client *mongo.Client, index IndexInfo) (time.Time, error) {
+	elements, err := index.Key.Elements()
+	if err != nil || len(elements) != 1 {
+		return time.Time{}, err
+	}
+	field := elements[0].Key()
+
+	// the range only matches dates, the only values the TTL monitor deletes
+	filter := bson.M{field: bson.M{"$gte": primitive.DateTime(math.MinInt64)}}
+	opts := options.FindOne().
+		SetHint(index.Name).
+		SetSort(bson.D{{Key: field, Value: 1}}).
+		SetProjection(bson.D{{Key: "_id", Value: 0}, {Key: field, Value: 1}})
+	doc, err := client.Database(index.Database).Collection(index.Collection).FindOne(context.TODO(), filter, opts).DecodeBytes()
+	if err == mongo.ErrNoDocuments {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	v, err := doc.LookupErr(strings.Split(field, ".")...)
+	if err != nil {
+		return time.Time{}, nil
+	}
+	if ms, ok := v.DateTimeOK(); ok {
+		return time.Unix(ms/1000, ms%1000*int64(time.Millisecond)), nil
+	}
+	return time.Time{}, nil
+}
+
+var (
+	logSuppressTTL = make(map[string]bool)
+)
+
+// GetTTLLagStatList returns the lag of the TTL indexes of the collections selected by filter
+func GetTTLLagStatList(client *mongo.Client, filter shared.NamespaceFilter) *TTLLagStatList {
+	namespaces, err := shared.ListCollectionNamespaces(client, filter)
+	if err != nil {
+		_, logSFound := logSuppressTTL[""]
+		if !logSFound {
+			log.Errorf("%s. TTL index lag will not be collected. This log message will be suppressed from now.", err)
+			logSuppressTTL[""] = true
+		}
+		return nil
+	}
+	delete(logSuppressTTL, "")
+
+	ttlLagList := &TTLLagStatList{Now: time.Now()}
+	for _, ns := range namespaces {
+		key := ns.Database + "." + ns.Collection
+		indexes, err := listIndexes(client, ns.Database, ns.Collection)
+		if err != nil {
+			_, logSFound := logSuppressTTL[key]
+			if !logSFound {
+				log.Errorf("%s. TTL index lag will not be collected for this collection. This log message will be suppressed from now.", err)
+				logSuppressTTL[key] = true
+			}
+			continue
+		}
+		delete(logSuppressTTL, key)
+
+		for _, index := range indexes {
+			if _, ttl := index.TTL(); !ttl {
+				continue
+			}
+			// hidden indexes (4.4+) can't be hinted, and are still used by the TTL monitor
+			if rawValueIsTrue(index.Hidden) {
+				continue
+			}
+			indexKey := key + "." + index.Name
+			oldest, err := getOldestIndexedDate(client, index)
+			if err != nil {
+				_, logSFound := logSuppressTTL[indexKey]
+				if !logSFound {
+					log.Errorf("Could not get the oldest date of TTL index %s on %s, reason: %v. This log message will be suppressed from now.", index.Name, key, err)
+					logSuppressTTL[indexKey] = true
+				}
+				continue
+			}
+			delete(logSuppressTTL, indexKey)
+			ttlLagList.Items = append(ttlLagList.Items, TTLLagStats{Index: index, Oldest: oldest})
+		}
+	}
+	return ttlLagList
+}
@@ -0,0 +1,41 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/percona/mongodb_exporter/testutils"
+)
+
+func TestTTLLag(t *testing.T) {
+	now := time.Date(2019, 7, 1, 12, 0, 0, 0, time.UTC)
+	index := decodeIndexInfo(t, bson.D{{Key: "key", Value: bson.D{{Key: "createdAt", Value: int32(1)}}}, {Key: "name", Value: "createdAt_1"}, {Key: "expireAfterSeconds", Value: int32(3600)}})
+
+	list := &TTLLagStatList{Now: now, Items: []TTLLagStats{
+		{Index: index, Oldest: now.Add(-3 * time.Hour)},
+	}}
+	assert.Equal(t, 7200.0, list.Items[0].Lag(now))
+	assert.Equal(t, 0.0, (&TTLLagStats{Index: index, Oldest: now.Add(-time.Minute)}).Lag(now))
+	assert.Equal(t, 0.0, (&TTLLagStats{Index: index}).Lag(now))
+
+	assert.Equal(t, map[string]float64{
+		"mongodb_mongod_index_ttl_lag_seconds/createdAt_1": 7200,
+	}, testutils.CollectMetrics(list.Export, "index"))
+}
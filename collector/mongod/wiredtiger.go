@@ -16,6 +16,7 @@ package mongod
 
 import (
 	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 var (
@@ -233,6 +234,7 @@ func (stats *WTCacheStats) Export(ch chan<- prometheus.Metric) {
 
 func (stats *WTCacheStats) Describe(ch chan<- *prometheus.Desc) {
 	ch <- wtCachePagesTotalDesc
+	ch <- wtCacheBytesTotalDesc
 	ch <- wtCacheEvictedTotalDesc
 	wtCachePages.Describe(ch)
 	wtCacheBytes.Describe(ch)
@@ -367,6 +369,20 @@ type WiredTigerStats struct {
 	Session                *WTSessionStats                `bson:"session"`
 	Transaction            *WTTransactionStats            `bson:"transaction"`
 	ConcurrentTransactions *WTConcurrentTransactionsStats `bson:"concurrentTransactions"`
+
+	// raw keeps the whole section for the statistics mapped in wtStats.
+	raw bson.Raw
+}
+
+// UnmarshalBSON decodes the typed WiredTiger stats and keeps a copy of the raw section.
+func (stats *WiredTigerStats) UnmarshalBSON(data []byte) error {
+	type wiredTigerStats WiredTigerStats
+	if err := bson.Unmarshal(data, (*wiredTigerStats)(stats)); err != nil {
+		return err
+	}
+	stats.raw = make(bson.Raw, len(data))
+	copy(stats.raw, data)
+	return nil
 }
 
 func (stats *WiredTigerStats) Describe(ch chan<- *prometheus.Desc) {
@@ -388,6 +404,9 @@ func (stats *WiredTigerStats) Describe(ch chan<- *prometheus.Desc) {
 	if stats.ConcurrentTransactions != nil {
 		stats.ConcurrentTransactions.Describe(ch)
 	}
+	for _, desc := range wtStatsDescs {
+		ch <- desc
+	}
 }
 
 func (stats *WiredTigerStats) Export(ch chan<- prometheus.Metric) {
@@ -409,6 +428,7 @@ func (stats *WiredTigerStats) Export(ch chan<- prometheus.Metric) {
 	if stats.ConcurrentTransactions != nil {
 		stats.ConcurrentTransactions.Export(ch)
 	}
+	exportWTStats(stats.raw, ch)
 
 	wtCachePages.Collect(ch)
 	wtCacheBytes.Collect(ch)
@@ -0,0 +1,350 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// wtDesc is a shortcut to create WiredTiger descriptors.
+func wtDesc(subsystem, name, help string, labels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(Namespace, subsystem, name), help, labels, nil)
+}
+
+var (
+	wtCacheEvictionPagesTotalDesc         = wtDesc("wiredtiger_cache", "eviction_pages_total", "The total number of pages evicted from the WiredTiger Cache by evicting thread and reason", "type")
+	wtCacheEvictionQueuedPagesTotalDesc   = wtDesc("wiredtiger_cache", "eviction_queued_pages_total", "The total number of pages queued for eviction in the WiredTiger Cache", "type")
+	wtCacheEvictionFailuresTotalDesc      = wtDesc("wiredtiger_cache", "eviction_failures_total", "The total number of times WiredTiger Cache eviction could not make progress", "type")
+	wtCacheEvictionWorkerThreadsDesc      = wtDesc("wiredtiger_cache", "eviction_worker_threads", "The number of active and stable WiredTiger eviction worker threads", "type")
+	wtCacheEvictionAggressiveDesc         = wtDesc("wiredtiger_cache", "eviction_aggressive_mode", "Whether WiredTiger Cache eviction currently operates in aggressive mode")
+	wtCacheEvictionEmptyScoreDesc         = wtDesc("wiredtiger_cache", "eviction_empty_score", "The WiredTiger eviction empty score")
+	wtCacheEvictionStateDesc              = wtDesc("wiredtiger_cache", "eviction_state", "The WiredTiger eviction state flags")
+	wtCacheEvictionWalkFilesDesc          = wtDesc("wiredtiger_cache", "eviction_walk_files", "The number of files with active eviction walks")
+	wtCacheMaxPageSizeAtEvictionDesc      = wtDesc("wiredtiger_cache", "eviction_max_page_size_bytes", "The maximum page size seen at eviction in bytes")
+	wtCacheApplicationThreadsIOTotalDesc  = wtDesc("wiredtiger_cache", "application_threads_io_total", "The total number of pages read into/written from the WiredTiger Cache by application threads", "type")
+	wtCacheApplicationThreadsIOTimeDesc   = wtDesc("wiredtiger_cache", "application_threads_io_microseconds_total", "The total time in microseconds application threads spent reading pages into/writing pages from the WiredTiger Cache", "type")
+	wtCacheOperationsTimedOutTotalDesc    = wtDesc("wiredtiger_cache", "operations_timed_out_total", "The total number of operations that timed out waiting for space in the WiredTiger Cache")
+	wtCachePressureBytesDesc              = wtDesc("wiredtiger_cache", "pressure_bytes", "The current size of data in the WiredTiger Cache by origin in bytes", "type")
+	wtCacheOverflowScoreDesc              = wtDesc("wiredtiger_cache", "overflow_score", "The WiredTiger cache overflow score")
+	wtDataHandlesActiveDesc               = wtDesc("wiredtiger_data_handle", "active", "The number of WiredTiger connection data handles currently active")
+	wtDataHandleSweepsTotalDesc           = wtDesc("wiredtiger_data_handle", "sweeps_total", "The total number of WiredTiger data handle sweeps", "type")
+	wtDataHandleSweptTotalDesc            = wtDesc("wiredtiger_data_handle", "swept_total", "The total number of WiredTiger data handles closed or removed by sweeps", "type")
+	wtConnectionFilesOpenDesc             = wtDesc("wiredtiger_connection", "files_open", "The number of files currently open by WiredTiger")
+	wtConnectionIOTotalDesc               = wtDesc("wiredtiger_connection", "io_total", "The total number of read, write and fsync I/Os issued by WiredTiger", "type")
+	wtConnectionMemoryTotalDesc           = wtDesc("wiredtiger_connection", "memory_operations_total", "The total number of memory allocations, frees and re-allocations done by WiredTiger", "type")
+	wtConnectionMutexCallsTotalDesc       = wtDesc("wiredtiger_connection", "mutex_calls_total", "The total number of pthread mutex calls done by WiredTiger", "type")
+	wtCursorCallsTotalDesc                = wtDesc("wiredtiger_cursor", "calls_total", "The total number of WiredTiger cursor calls", "type")
+	wtLockAcquisitionsTotalDesc           = wtDesc("wiredtiger_lock", "acquisitions_total", "The total number of WiredTiger internal lock acquisitions", "type")
+	wtLockWaitTimeTotalDesc               = wtDesc("wiredtiger_lock", "wait_microseconds_total", "The total time in microseconds application and internal threads waited for WiredTiger internal locks", "type", "thread")
+	wtReconciliationCallsTotalDesc        = wtDesc("wiredtiger_reconciliation", "calls_total", "The total number of WiredTiger page reconciliation calls", "type")
+	wtReconciliationPagesDeletedTotalDesc = wtDesc("wiredtiger_reconciliation", "pages_deleted_total", "The total number of pages deleted by WiredTiger reconciliation", "type")
+	wtReconciliationSplitAwaitingDesc     = wtDesc("wiredtiger_reconciliation", "split_awaiting_free", "The bytes and objects of split pages currently awaiting free", "type")
+	wtThreadYieldTotalDesc                = wtDesc("wiredtiger_thread_yield", "total", "The total number of times WiredTiger threads yielded or were blocked", "type")
+	wtThreadYieldTimeTotalDesc            = wtDesc("wiredtiger_thread_yield", "microseconds_total", "The total time in microseconds WiredTiger threads spent yielding, sleeping or evicting", "type")
+	wtThreadStateActiveCallsDesc          = wtDesc("wiredtiger_thread_state", "active_filesystem_calls", "The number of WiredTiger filesystem calls currently active", "type")
+	wtTransactionsPinnedRangeDesc         = wtDesc("wiredtiger_transactions", "pinned_range", "The range of WiredTiger transaction IDs currently pinned", "type")
+	wtTransactionsPinnedTimestampsDesc    = wtDesc("wiredtiger_transactions", "pinned_timestamp_range", "The range of WiredTiger timestamps currently pinned")
+	wtTransactionsSyncCallsTotalDesc      = wtDesc("wiredtiger_transactions", "sync_calls_total", "The total number of WiredTiger transaction sync calls")
+	wtTransactionsCheckpointGenDesc       = wtDesc("wiredtiger_transactions", "checkpoint_generation", "The current WiredTiger checkpoint generation")
+	wtLogPreallocatedFilesTotalDesc       = wtDesc("wiredtiger_log", "preallocated_files_total", "The total number of pre-allocated WiredTiger log files prepared and used", "type")
+	wtLogForceWritesTotalDesc             = wtDesc("wiredtiger_log", "force_writes_total", "The total number of WiredTiger log force write operations")
+	wtLogConsolidatedBytesTotalDesc       = wtDesc("wiredtiger_log", "consolidated_bytes_total", "The total number of bytes consolidated by WiredTiger logging")
+	wtStatDesc                            = wtDesc("wiredtiger", "stat", "The value of a WiredTiger statistic not exported as a dedicated metric, by section and statistic", "section", "stat")
+)
+
+// wtStat maps a single statistic of the wiredTiger serverStatus section to a metric.
+type wtStat struct {
+	section     string
+	key         string
+	desc        *prometheus.Desc
+	valueType   prometheus.ValueType
+	labelValues []string
+}
+
+func wtCounter(section, key string, desc *prometheus.Desc, labelValues ...string) wtStat {
+	return wtStat{section: section, key: key, desc: desc, valueType: prometheus.CounterValue, labelValues: labelValues}
+}
+
+func wtGauge(section, key string, desc *prometheus.Desc, labelValues ...string) wtStat {
+	return wtStat{section: section, key: key, desc: desc, valueType: prometheus.GaugeValue, labelValues: labelValues}
+}
+
+// wtStats lists the WiredTiger statistics that are not decoded by the typed WT*Stats structs.
+// Statistics missing from the server's version are skipped.
+var wtStats = []wtStat{
+	// cache: eviction
+	wtCounter("cache", "eviction worker thread evicting pages", wtCacheEvictionPagesTotalDesc, "worker"),
+	wtCounter("cache", "eviction server evicting pages", wtCacheEvictionPagesTotalDesc, "server"),
+	wtCounter("cache", "pages evicted by application threads", wtCacheEvictionPagesTotalDesc, "application"),
+	wtCounter("cache", "internal pages evicted", wtCacheEvictionPagesTotalDesc, "internal"),
+	wtCounter("cache", "pages evicted because they exceeded the in-memory maximum count", wtCacheEvictionPagesTotalDesc, "exceeded_max_in_memory"),
+	wtCounter("cache", "pages evicted because they had chains of deleted items count", wtCacheEvictionPagesTotalDesc, "deleted_chains"),
+	wtCounter("cache", "pages queued for eviction", wtCacheEvictionQueuedPagesTotalDesc, "normal"),
+	wtCounter("cache", "pages queued for urgent eviction", wtCacheEvictionQueuedPagesTotalDesc, "urgent"),
+	wtCounter("cache", "pages selected for eviction unable to be evicted", wtCacheEvictionFailuresTotalDesc, "unable_to_evict"),
+	wtCounter("cache", "hazard pointer blocked page eviction", wtCacheEvictionFailuresTotalDesc, "hazard_pointer_blocked"),
+	wtCounter("cache", "eviction server unable to reach eviction goal", wtCacheEvictionFailuresTotalDesc, "goal_not_reached"),
+	wtCounter("cache", "eviction walks abandoned", wtCacheEvictionFailuresTotalDesc, "walks_abandoned"),
+	wtGauge("cache", "eviction worker thread active", wtCacheEvictionWorkerThreadsDesc, "active"),
+	wtGauge("cache", "eviction worker thread stable number", wtCacheEvictionWorkerThreadsDesc, "stable"),
+	wtGauge("cache", "eviction currently operating in aggressive mode", wtCacheEvictionAggressiveDesc),
+	wtGauge("cache", "eviction empty score", wtCacheEvictionEmptyScoreDesc),
+	wtGauge("cache", "eviction state", wtCacheEvictionStateDesc),
+	wtGauge("cache", "files with active eviction walks", wtCacheEvictionWalkFilesDesc),
+	wtGauge("cache", "maximum page size at eviction", wtCacheMaxPageSizeAtEvictionDesc),
+	wtCounter("cache", "application threads page read from disk to cache count", wtCacheApplicationThreadsIOTotalDesc, "read"),
+	wtCounter("cache", "application threads page write from cache to disk count", wtCacheApplicationThreadsIOTotalDesc, "write"),
+	wtCounter("cache", "application threads page read from disk to cache time (usecs)", wtCacheApplicationThreadsIOTimeDesc, "read"),
+	wtCounter("cache", "application threads page write from cache to disk time (usecs)", wtCacheApplicationThreadsIOTimeDesc, "write"),
+	wtCounter("cache", "operations timed out waiting for space in cache", wtCacheOperationsTimedOutTotalDesc),
+
+	// cache: pressure
+	wtGauge("cache", "bytes belonging to page images in the cache", wtCachePressureBytesDesc, "page_images"),
+	wtGauge("cache", "bytes not belonging to page images in the cache", wtCachePressureBytesDesc, "other"),
+	wtGauge("cache", "bytes belonging to the cache overflow table in the cache", wtCachePressureBytesDesc, "overflow_table"),
+	wtGauge("cache", "bytes belonging to the history store table in the cache", wtCachePressureBytesDesc, "history_store"),
+	wtGauge("cache", "cache overflow score", wtCacheOverflowScoreDesc),
+
+	// data-handle
+	wtGauge("data-handle", "connection data handles currently active", wtDataHandlesActiveDesc),
+	wtCounter("data-handle", "connection sweeps", wtDataHandleSweepsTotalDesc, "connection"),
+	wtCounter("data-handle", "session sweep attempts", wtDataHandleSweepsTotalDesc, "session"),
+	wtCounter("data-handle", "connection sweep dhandles closed", wtDataHandleSweptTotalDesc, "connection_closed"),
+	wtCounter("data-handle", "connection sweep dhandles removed from hash list", wtDataHandleSweptTotalDesc, "connection_removed"),
+	wtCounter("data-handle", "session dhandles swept", wtDataHandleSweptTotalDesc, "session"),
+
+	// connection
+	wtGauge("connection", "files currently open", wtConnectionFilesOpenDesc),
+	wtCounter("connection", "total read I/Os", wtConnectionIOTotalDesc, "read"),
+	wtCounter("connection", "total write I/Os", wtConnectionIOTotalDesc, "write"),
+	wtCounter("connection", "total fsync I/Os", wtConnectionIOTotalDesc, "fsync"),
+	wtCounter("connection", "memory allocations", wtConnectionMemoryTotalDesc, "allocations"),
+	wtCounter("connection", "memory frees", wtConnectionMemoryTotalDesc, "frees"),
+	wtCounter("connection", "memory re-allocations", wtConnectionMemoryTotalDesc, "reallocations"),
+	wtCounter("connection", "pthread mutex condition wait calls", wtConnectionMutexCallsTotalDesc, "condition_wait"),
+	wtCounter("connection", "pthread mutex shared lock read-lock calls", wtConnectionMutexCallsTotalDesc, "shared_read"),
+	wtCounter("connection", "pthread mutex shared lock write-lock calls", wtConnectionMutexCallsTotalDesc, "shared_write"),
+
+	// cursor
+	wtCounter("cursor", "cursor create calls", wtCursorCallsTotalDesc, "create"),
+	wtCounter("cursor", "cursor insert calls", wtCursorCallsTotalDesc, "insert"),
+	wtCounter("cursor", "cursor modify calls", wtCursorCallsTotalDesc, "modify"),
+	wtCounter("cursor", "cursor next calls", wtCursorCallsTotalDesc, "next"),
+	wtCounter("cursor", "cursor prev calls", wtCursorCallsTotalDesc, "prev"),
+	wtCounter("cursor", "cursor remove calls", wtCursorCallsTotalDesc, "remove"),
+	wtCounter("cursor", "cursor reset calls", wtCursorCallsTotalDesc, "reset"),
+	wtCounter("cursor", "cursor restarted searches", wtCursorCallsTotalDesc, "restarted_search"),
+	wtCounter("cursor", "cursor search calls", wtCursorCallsTotalDesc, "search"),
+	wtCounter("cursor", "cursor search near calls", wtCursorCallsTotalDesc, "search_near"),
+	wtCounter("cursor", "cursor update calls", wtCursorCallsTotalDesc, "update"),
+	wtCounter("cursor", "truncate calls", wtCursorCallsTotalDesc, "truncate"),
+
+	// lock
+	wtCounter("lock", "checkpoint lock acquisitions", wtLockAcquisitionsTotalDesc, "checkpoint"),
+	wtCounter("lock", "dhandle read lock acquisitions", wtLockAcquisitionsTotalDesc, "dhandle_read"),
+	wtCounter("lock", "dhandle write lock acquisitions", wtLockAcquisitionsTotalDesc, "dhandle_write"),
+	wtCounter("lock", "metadata lock acquisitions", wtLockAcquisitionsTotalDesc, "metadata"),
+	wtCounter("lock", "schema lock acquisitions", wtLockAcquisitionsTotalDesc, "schema"),
+	wtCounter("lock", "table read lock acquisitions", wtLockAcquisitionsTotalDesc, "table_read"),
+	wtCounter("lock", "table write lock acquisitions", wtLockAcquisitionsTotalDesc, "table_write"),
+	wtCounter("lock", "txn global read lock acquisitions", wtLockAcquisitionsTotalDesc, "txn_global_read"),
+	wtCounter("lock", "txn global write lock acquisitions", wtLockAcquisitionsTotalDesc, "txn_global_write"),
+	wtCounter("lock", "checkpoint lock application thread wait time (usecs)", wtLockWaitTimeTotalDesc, "checkpoint", "application"),
+	wtCounter("lock", "checkpoint lock internal thread wait time (usecs)", wtLockWaitTimeTotalDesc, "checkpoint", "internal"),
+	wtCounter("lock", "dhandle lock application thread time waiting (usecs)", wtLockWaitTimeTotalDesc, "dhandle", "application"),
+	wtCounter("lock", "dhandle lock internal thread time waiting (usecs)", wtLockWaitTimeTotalDesc, "dhandle", "internal"),
+	wtCounter("lock", "metadata lock application thread wait time (usecs)", wtLockWaitTimeTotalDesc, "metadata", "application"),
+	wtCounter("lock", "metadata lock internal thread wait time (usecs)", wtLockWaitTimeTotalDesc, "metadata", "internal"),
+	wtCounter("lock", "schema lock application thread wait time (usecs)", wtLockWaitTimeTotalDesc, "schema", "application"),
+	wtCounter("lock", "schema lock internal thread wait time (usecs)", wtLockWaitTimeTotalDesc, "schema", "internal"),
+	wtCounter("lock", "table lock application thread time waiting for the table lock (usecs)", wtLockWaitTimeTotalDesc, "table", "application"),
+	wtCounter("lock", "table lock internal thread time waiting for the table lock (usecs)", wtLockWaitTimeTotalDesc, "table", "internal"),
+	wtCounter("lock", "txn global lock application thread time waiting (usecs)", wtLockWaitTimeTotalDesc, "txn_global", "application"),
+	wtCounter("lock", "txn global lock internal thread time waiting (usecs)", wtLockWaitTimeTotalDesc, "txn_global", "internal"),
+
+	// reconciliation
+	wtCounter("reconciliation", "page reconciliation calls", wtReconciliationCallsTotalDesc, "page"),
+	wtCounter("reconciliation", "page reconciliation calls for eviction", wtReconciliationCallsTotalDesc, "eviction"),
+	wtCounter("reconciliation", "pages deleted", wtReconciliationPagesDeletedTotalDesc, "normal"),
+	wtCounter("reconciliation", "fast-path pages deleted", wtReconciliationPagesDeletedTotalDesc, "fast_path"),
+	wtGauge("reconciliation", "split bytes currently awaiting free", wtReconciliationSplitAwaitingDesc, "bytes"),
+	wtGauge("reconciliation", "split objects currently awaiting free", wtReconciliationSplitAwaitingDesc, "objects"),
+
+	// thread-yield
+	wtCounter("thread-yield", "page acquire busy blocked", wtThreadYieldTotalDesc, "page_acquire_busy"),
+	wtCounter("thread-yield", "page acquire eviction blocked", wtThreadYieldTotalDesc, "page_acquire_eviction"),
+	wtCounter("thread-yield", "page acquire locked blocked", wtThreadYieldTotalDesc, "page_acquire_locked"),
+	wtCounter("thread-yield", "page acquire read blocked", wtThreadYieldTotalDesc, "page_acquire_read"),
+	wtCounter("thread-yield", "data handle lock yielded", wtThreadYieldTotalDesc, "data_handle_lock"),
+	wtCounter("thread-yield", "log server sync yielded for log write", wtThreadYieldTotalDesc, "log_server_sync"),
+	wtCounter("thread-yield", "page access yielded due to prepare state change", wtThreadYieldTotalDesc, "page_prepare_state"),
+	wtCounter("thread-yield", "page reconciliation yielded due to child modification", wtThreadYieldTotalDesc, "page_reconciliation_child"),
+	wtCounter("thread-yield", "connection close yielded for lsm manager shutdown", wtThreadYieldTotalDesc, "lsm_manager_shutdown"),
+	wtCounter("thread-yield", "application thread time evicting (usecs)", wtThreadYieldTimeTotalDesc, "application_evicting"),
+	wtCounter("thread-yield", "application thread time waiting for cache (usecs)", wtThreadYieldTimeTotalDesc, "application_waiting_for_cache"),
+	wtCounter("thread-yield", "page acquire time sleeping (usecs)", wtThreadYieldTimeTotalDesc, "page_acquire_sleeping"),
+	wtCounter("thread-yield", "page delete rollback time sleeping for state change (usecs)", wtThreadYieldTimeTotalDesc, "page_delete_rollback_sleeping"),
+	wtCounter("thread-yield", "get reference for page index and slot time sleeping (usecs)", wtThreadYieldTimeTotalDesc, "page_index_slot_sleeping"),
+
+	// thread-state
+	wtGauge("thread-state", "active filesystem fsync calls", wtThreadStateActiveCallsDesc, "fsync"),
+	wtGauge("thread-state", "active filesystem read calls", wtThreadStateActiveCallsDesc, "read"),
+	wtGauge("thread-state", "active filesystem write calls", wtThreadStateActiveCallsDesc, "write"),
+
+	// transaction
+	wtGauge("transaction", "transaction range of IDs currently pinned", wtTransactionsPinnedRangeDesc, "all"),
+	wtGauge("transaction", "transaction range of IDs currently pinned by a checkpoint", wtTransactionsPinnedRangeDesc, "checkpoint"),
+	wtGauge("transaction", "transaction range of IDs currently pinned by named snapshots", wtTransactionsPinnedRangeDesc, "named_snapshots"),
+	wtGauge("transaction", "transaction range of timestamps currently pinned", wtTransactionsPinnedTimestampsDesc),
+	wtCounter("transaction", "transaction sync calls", wtTransactionsSyncCallsTotalDesc),
+	wtGauge("transaction", "transaction checkpoint generation", wtTransactionsCheckpointGenDesc),
+
+	// log
+	wtCounter("log", "pre-allocated log files prepared", wtLogPreallocatedFilesTotalDesc, "prepared"),
+	wtCounter("log", "pre-allocated log files used", wtLogPreallocatedFilesTotalDesc, "used"),
+	wtCounter("log", "log force write operations", wtLogForceWritesTotalDesc),
+	wtCounter("log", "logging bytes consolidated", wtLogConsolidatedBytesTotalDesc),
+}
+
+// wtStatsDescs lists every distinct descriptor of wtStats and the descriptor of the other statistics.
+var wtStatsDescs = func() []*prometheus.Desc {
+	descs := []*prometheus.Desc{wtStatDesc}
+	seen := make(map[*prometheus.Desc]bool)
+	for _, stat := range wtStats {
+		if !seen[stat.desc] {
+			seen[stat.desc] = true
+			descs = append(descs, stat.desc)
+		}
+	}
+	return descs
+}()
+
+// wtExcludedStats are the statistics of the wiredTiger section deliberately not exported,
+// by section or "section/key" prefix, with the reason.
+var wtExcludedStats = map[string]string{
+	"LSM":                      "LSM trees are not used by MongoDB",
+	"async":                    "the asynchronous API is not used by MongoDB",
+	"perf":                     "latency histogram buckets",
+	"capacity":                 "I/O throttling of the background threads",
+	"checkpoint-cleanup":       "checkpoint cleanup of obsolete pages",
+	"snapshot-window-settings": "MongoDB settings, not statistics",
+	"oplog":                    "oplog truncation internals",
+	"cache/eviction walk target pages histogram": "histogram buckets",
+}
+
+// wtExcluded returns true if the "section/key" statistic is in wtExcludedStats.
+func wtExcluded(path string) bool {
+	for prefix := range wtExcludedStats {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") || strings.HasPrefix(path, prefix+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// wtMappedStats holds the "section/key" of every statistic exported by the typed WiredTiger stats and wtStats.
+var wtMappedStats = func() map[string]bool {
+	mapped := make(map[string]bool)
+	var walk func(t reflect.Type, prefix string)
+	walk = func(t reflect.Type, prefix string) {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("bson"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			if field.Type.Kind() == reflect.Ptr {
+				walk(field.Type, prefix+name+"/")
+				continue
+			}
+			mapped[prefix+name] = true
+		}
+	}
+	walk(reflect.TypeOf(WiredTigerStats{}), "")
+	for _, stat := range wtStats {
+		mapped[stat.section+"/"+stat.key] = true
+	}
+	return mapped
+}()
+
+// exportWTStats exports the statistics of wtStats found in the raw wiredTiger (or inMemory) section,
+// and every other numeric statistic of its sections which is not excluded as mongodb_mongod_wiredtiger_stat.
+func exportWTStats(raw bson.Raw, ch chan<- prometheus.Metric) {
+	if len(raw) == 0 {
+		return
+	}
+	for _, stat := range wtStats {
+		v, err := raw.LookupErr(stat.section, stat.key)
+		if err != nil {
+			continue
+		}
+		f, ok := rawValueToFloat64(v)
+		if !ok {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(stat.desc, stat.valueType, f, stat.labelValues...)
+	}
+
+	walkWTStats(raw, "", func(path string, value float64) {
+		if wtMappedStats[path] || wtExcluded(path) {
+			return
+		}
+		i := strings.IndexByte(path, '/')
+		if i < 0 {
+			// not part of a section
+			return
+		}
+		ch <- prometheus.MustNewConstMetric(wtStatDesc, prometheus.UntypedValue, value, path[:i], path[i+1:])
+	})
+}
+
+// walkWTStats calls f with the "section/key" path and the value of every numeric statistic of doc.
+func walkWTStats(doc bson.Raw, prefix string, f func(path string, value float64)) {
+	elements, err := doc.Elements()
+	if err != nil {
+		return
+	}
+	for _, element := range elements {
+		path := prefix + element.Key()
+		if sub, ok := element.Value().DocumentOK(); ok {
+			walkWTStats(sub, path+"/", f)
+			continue
+		}
+		if value, ok := rawValueToFloat64(element.Value()); ok {
+			f(path, value)
+		}
+	}
+}
+
+// rawValueToFloat64 converts a numeric BSON value to float64.
+func rawValueToFloat64(v bson.RawValue) (float64, bool) {
+	if i, ok := v.Int32OK(); ok {
+		return float64(i), true
+	}
+	if i, ok := v.Int64OK(); ok {
+		return float64(i), true
+	}
+	if f, ok := v.DoubleOK(); ok {
+		return f, true
+	}
+	return 0, false
+}
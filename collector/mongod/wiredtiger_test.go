@@ -0,0 +1,169 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongod
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/percona/exporter_shared/helpers"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// wtCollector exports the WiredTiger section of a stored serverStatus sample.
+type wtCollector struct {
+	stats *WiredTigerStats
+}
+
+func (c *wtCollector) Describe(ch chan<- *prometheus.Desc) { c.stats.Describe(ch) }
+func (c *wtCollector) Collect(ch chan<- prometheus.Metric) { c.stats.Export(ch) }
+
+// wtSamples are the serverStatus samples the WiredTiger mapping is checked against.
+// They must be captured from a server with mongosh --quiet --eval 'EJSON.stringify(db.serverStatus())',
+// with the host names and addresses removed.
+var wtSamples = []string{
+	"server_status_wiredtiger_3.6.json",
+	"server_status_wiredtiger_4.2.json",
+	"server_status_wiredtiger_4.4.json",
+	"server_status_wiredtiger_5.0.json",
+	"server_status_inmemory_4.4.json",
+}
+
+// uncoveredWTStats returns the numeric statistics of the wiredTiger section which are neither exported
+// nor deliberately excluded.
+func uncoveredWTStats(section bson.Raw) []string {
+	exported := make(map[string]bool)
+	for _, m := range collectWTStats(section) {
+		if m.Name == "mongodb_mongod_wiredtiger_stat" {
+			exported[m.Labels["section"]+"/"+m.Labels["stat"]] = true
+		}
+	}
+
+	var uncovered []string
+	walkWTStats(section, "", func(path string, _ float64) {
+		if !exported[path] && !wtMappedStats[path] && !wtExcluded(path) {
+			uncovered = append(uncovered, path)
+		}
+	})
+	return uncovered
+}
+
+// collectWTStats returns the metrics exportWTStats sends for the section.
+func collectWTStats(section bson.Raw) []*helpers.Metric {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		exportWTStats(section, ch)
+		close(ch)
+	}()
+	var metrics []*helpers.Metric
+	for m := range ch {
+		metrics = append(metrics, helpers.ReadMetric(m))
+	}
+	return metrics
+}
+
+func TestExportWTStatsUnmapped(t *testing.T) {
+	data, err := bson.Marshal(bson.M{
+		"uri": "statistics:",
+		"cache": bson.M{
+			"bytes currently in the cache":                  int64(1),
+			"eviction worker thread evicting pages":         int64(2),
+			"eviction walk target pages histogram - 0-9":    int64(3),
+			"a statistic added by a new WiredTiger version": int64(4),
+		},
+		"LSM":                    bson.M{"sleep for LSM checkpoint throttle": int32(0)},
+		"concurrentTransactions": bson.M{"read": bson.M{"out": int32(0), "available": int32(128), "totalTickets": int32(128)}},
+		"connection":             bson.M{"auto adjusting condition resets": int64(5)},
+		"history-store":          bson.M{"history store table insert calls": int64(6)},
+	})
+	require.NoError(t, err)
+
+	stats := make(map[string]float64)
+	for _, m := range collectWTStats(data) {
+		if m.Name == "mongodb_mongod_wiredtiger_stat" {
+			stats[m.Labels["section"]+"/"+m.Labels["stat"]] = m.Value
+		}
+	}
+	assert.Equal(t, map[string]float64{
+		"cache/a statistic added by a new WiredTiger version": 4,
+		"connection/auto adjusting condition resets":          5,
+		"history-store/history store table insert calls":      6,
+	}, stats)
+	assert.Empty(t, uncoveredWTStats(data))
+}
+
+func TestWiredTigerStatsSamples(t *testing.T) {
+	for _, sample := range wtSamples {
+		t.Run(sample, func(t *testing.T) {
+			data, err := ioutil.ReadFile(filepath.Join("testdata", sample))
+			require.NoError(t, err, "capture %s from a server", sample)
+			var doc bson.Raw
+			require.NoError(t, bson.UnmarshalExtJSON(data, false, &doc))
+			status := &ServerStatus{}
+			require.NoError(t, bson.Unmarshal(doc, status))
+
+			stats, key := status.WiredTiger, "wiredTiger"
+			if stats == nil {
+				stats, key = status.InMemory, "inMemory"
+			}
+			require.NotNil(t, stats)
+			section := doc.Lookup(key).Document()
+
+			// the systematic mapping of the full section
+			assert.Empty(t, uncoveredWTStats(section), "statistics neither exported nor in wtExcludedStats")
+
+			registry := prometheus.NewPedanticRegistry()
+			require.NoError(t, registry.Register(&wtCollector{stats: stats}))
+			families, err := registry.Gather()
+			require.NoError(t, err)
+
+			exported := make(map[string]bool)
+			for _, mf := range families {
+				for _, m := range mf.GetMetric() {
+					exported[metricKey(mf.GetName(), m.GetLabel())] = true
+				}
+			}
+			for _, stat := range wtStats {
+				if _, err := section.LookupErr(stat.section, stat.key); err != nil {
+					continue
+				}
+				m := helpers.ReadMetric(prometheus.MustNewConstMetric(stat.desc, stat.valueType, 0, stat.labelValues...))
+				var labels []*dto.LabelPair
+				for name, value := range m.Labels {
+					labels = append(labels, &dto.LabelPair{Name: proto.String(name), Value: proto.String(value)})
+				}
+				assert.True(t, exported[metricKey(m.Name, labels)], "%s/%s not exported", stat.section, stat.key)
+			}
+		})
+	}
+}
+
+// metricKey formats a metric name and its labels as a map key.
+func metricKey(name string, labels []*dto.LabelPair) string {
+	pairs := make([]string, 0, len(labels))
+	for _, l := range labels {
+		pairs = append(pairs, l.GetName()+"="+l.GetValue())
+	}
+	sort.Strings(pairs)
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
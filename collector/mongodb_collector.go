@@ -45,10 +45,20 @@ type MongodbCollectorOpts struct {
 	CollectCollectionMetrics bool
 	CollectTopMetrics        bool
 	CollectIndexUsageStats   bool
+	IndexUnusedAge           time.Duration
 	CollectConnPoolStats     bool
 	SocketTimeout            time.Duration
 	SyncTimeout              time.Duration
 	AuthentificationDB       string
+	LogFile                  string
+	CommandsAllowlist        []string
+	CollectIndexDetails      bool
+	CollectIndexInfo         bool
+	CollectTTLLag            bool
+	CollectIndexBuilds       bool
+	OplogChurnWindow         time.Duration
+	Namespaces               []string
+	ReplSetTags              []string
 }
 
 func (in *MongodbCollectorOpts) toSessionOps() *shared.MongoSessionOpts {
@@ -78,6 +88,16 @@ type MongodbCollector struct {
 
 	mongoSessLock sync.Mutex
 	mongoClient   *mongo.Client
+
+	clusterRoleLock sync.Mutex
+	clusterRole     string
+
+	logTailer      *mongod.LogTailer
+	primaryChanges *mongod.PrimaryChangeTracker
+	rollbacks      *mongod.RollbackTracker
+	oplogChurn     *mongod.OplogChurnCache
+
+	shardingChangelog *mongos.ShardingChangelogCounter
 }
 
 // NewMongodbCollector returns a new instance of a MongodbCollector.
@@ -114,6 +134,22 @@ func NewMongodbCollector(opts *MongodbCollectorOpts) *MongodbCollector {
 			Name:      "up",
 			Help:      "Whether MongoDB is up.",
 		}),
+
+		primaryChanges: mongod.NewPrimaryChangeTracker(),
+		rollbacks:      mongod.NewRollbackTracker(),
+
+		shardingChangelog: mongos.NewShardingChangelogCounter(),
+
+		clusterRole: clusterRoleUnknown,
+	}
+
+	if opts.OplogChurnWindow > 0 {
+		exporter.oplogChurn = mongod.NewOplogChurnCache(opts.OplogChurnWindow)
+	}
+
+	if opts.LogFile != "" {
+		exporter.logTailer = mongod.NewLogTailer(opts.LogFile)
+		exporter.logTailer.Start()
 	}
 
 	return exporter
@@ -134,8 +170,12 @@ func (exporter *MongodbCollector) getClient() *mongo.Client {
 	return exporter.mongoClient
 }
 
-// Close cleanly closes the mongo session if it exists.
+// Close cleanly closes the mongo session if it exists and stops the log tailer.
 func (exporter *MongodbCollector) Close() {
+	if exporter.logTailer != nil {
+		exporter.logTailer.Stop()
+	}
+
 	exporter.mongoSessLock.Lock()
 	defer exporter.mongoSessLock.Unlock()
 
@@ -175,9 +215,34 @@ func (exporter *MongodbCollector) Describe(ch chan<- *prometheus.Desc) {
 }
 
 // Collect is called by the Prometheus registry when collecting metrics.
+// The metrics of MongoDB get the cluster_role label detected in the same scrape,
+// the exporter and log metrics are collected without it so that they keep their identity.
 // Part of prometheus.Collector interface.
 func (exporter *MongodbCollector) Collect(ch chan<- prometheus.Metric) {
-	exporter.scrape(ch)
+	metricCh := make(chan prometheus.Metric)
+	doneCh := make(chan struct{})
+
+	var metrics []prometheus.Metric
+	go func() {
+		for m := range metricCh {
+			metrics = append(metrics, m)
+		}
+		close(doneCh)
+	}()
+
+	exporter.scrape(metricCh)
+	close(metricCh)
+	<-doneCh
+
+	exporter.clusterRoleLock.Lock()
+	role := exporter.clusterRole
+	exporter.clusterRoleLock.Unlock()
+	withClusterRole(metrics, role).Collect(ch)
+
+	// log metrics are collected regardless of the MongoDB connection state
+	if exporter.logTailer != nil {
+		exporter.logTailer.Export(ch)
+	}
 
 	exporter.scrapesTotal.Collect(ch)
 	exporter.scrapeErrorsTotal.Collect(ch)
@@ -216,25 +281,38 @@ func (exporter *MongodbCollector) scrape(ch chan<- prometheus.Metric) {
 	}
 	exporter.mongoUp.Set(1)
 
-	var nodeType string
-	nodeType, err = shared.MongoSessionNodeType(mongoSess)
+	var nodeType, clusterRole string
+	nodeType, clusterRole, err = shared.MongoSessionNodeInfo(mongoSess)
 	if err != nil {
 		log.Errorf("Problem gathering the mongo node type: %s", err)
 		return
 	}
 
-	log.Debugf("Connected to: %s (node type: %s, server version: %s)", shared.RedactMongoUri(exporter.Opts.URI), nodeType, serverVersion)
+	// keep the last detected role if it could not be detected
+	exporter.clusterRoleLock.Lock()
+	if clusterRole != "" {
+		exporter.clusterRole = clusterRole
+	} else {
+		clusterRole = exporter.clusterRole
+	}
+	exporter.clusterRoleLock.Unlock()
+
+	log.Debugf("Connected to: %s (node type: %s, cluster role: %s, server version: %s)", shared.RedactMongoUri(exporter.Opts.URI), nodeType, clusterRole, serverVersion)
 	switch {
 	case nodeType == "mongos":
 		exporter.collectMongos(mongoSess, ch)
 	case nodeType == "mongod":
-		exporter.collectMongod(mongoSess, ch)
+		exporter.collectMongod(mongoSess, false, ch)
 	case nodeType == "replset":
-		exporter.collectMongodReplSet(mongoSess, ch)
+		exporter.collectMongodReplSet(mongoSess, serverVersion, ch)
 	default:
 		err = fmt.Errorf("Unrecognized node type %s", nodeType)
 		log.Error(err)
 	}
+
+	if clusterRole == shared.ClusterRoleConfigSvr {
+		exporter.collectConfigSvr(mongoSess, ch)
+	}
 }
 
 func (exporter *MongodbCollector) collectMongos(client *mongo.Client, ch chan<- prometheus.Metric) {
@@ -250,6 +328,10 @@ func (exporter *MongodbCollector) collectMongos(client *mongo.Client, ch chan<-
 		shardingStatus.Export(ch)
 	}
 
+	log.Debug("Collecting Sharding Changelog Events")
+	exporter.shardingChangelog.Update(client)
+	exporter.shardingChangelog.Export(ch)
+
 	if exporter.Opts.CollectDatabaseMetrics {
 		log.Debug("Collecting Database Status From Mongos")
 		dbStatList := mongos.GetDatabaseStatList(client)
@@ -266,6 +348,14 @@ func (exporter *MongodbCollector) collectMongos(client *mongo.Client, ch chan<-
 		}
 	}
 
+	if exporter.Opts.CollectIndexUsageStats {
+		log.Debug("Collecting Index Statistics From Mongos")
+		indexStatList := mongod.GetIndexUsageStatList(client, exporter.Opts.IndexUnusedAge)
+		if indexStatList != nil {
+			indexStatList.Export(ch)
+		}
+	}
+
 	if exporter.Opts.CollectConnPoolStats {
 		log.Debug("Collecting ConnPoolStats Metrics")
 		connPoolStats := commoncollector.GetConnPoolStats(client)
@@ -275,10 +365,28 @@ func (exporter *MongodbCollector) collectMongos(client *mongo.Client, ch chan<-
 	}
 }
 
-func (exporter *MongodbCollector) collectMongod(client *mongo.Client, ch chan<- prometheus.Metric) {
+// collectConfigSvr collects the sharding metadata of the config.* collections directly from a config server member.
+func (exporter *MongodbCollector) collectConfigSvr(client *mongo.Client, ch chan<- prometheus.Metric) {
+	log.Debug("Collecting Sharding Topology From Config Server")
+	topology := mongos.GetShardingTopoStatus(client)
+	if topology != nil {
+		topology.Export(ch)
+	}
+
+	log.Debug("Collecting Sharding Changelog From Config Server")
+	changelog := mongos.GetShardingChangelogStatus(client)
+	if changelog != nil {
+		changelog.Export(ch)
+	}
+	exporter.shardingChangelog.Update(client)
+	exporter.shardingChangelog.Export(ch)
+}
+
+func (exporter *MongodbCollector) collectMongod(client *mongo.Client, replSet bool, ch chan<- prometheus.Metric) {
 	log.Debug("Collecting Server Status")
 	serverStatus := mongod.GetServerStatus(client)
 	if serverStatus != nil {
+		serverStatus.FilterCommands(exporter.Opts.CommandsAllowlist)
 		serverStatus.Export(ch)
 	}
 
@@ -308,12 +416,44 @@ func (exporter *MongodbCollector) collectMongod(client *mongo.Client, ch chan<-
 
 	if exporter.Opts.CollectIndexUsageStats {
 		log.Debug("Collecting Index Statistics")
-		indexStatList := mongod.GetIndexUsageStatList(client)
+		indexStatList := mongod.GetIndexUsageStatList(client, exporter.Opts.IndexUnusedAge)
 		if indexStatList != nil {
 			indexStatList.Export(ch)
 		}
 	}
 
+	if exporter.Opts.CollectIndexDetails {
+		log.Debug("Collecting Index Details")
+		indexDetailsList := mongod.GetIndexDetailsStatList(client, exporter.Opts.Namespaces)
+		if indexDetailsList != nil {
+			indexDetailsList.Export(ch)
+		}
+	}
+
+	if exporter.Opts.CollectIndexInfo {
+		log.Debug("Collecting Index Definitions")
+		indexInfoList := mongod.GetIndexInfoList(client, exporter.Opts.Namespaces)
+		if indexInfoList != nil {
+			indexInfoList.Export(ch)
+		}
+	}
+
+	if exporter.Opts.CollectTTLLag {
+		log.Debug("Collecting TTL Index Lag")
+		ttlLagList := mongod.GetTTLLagStatList(client, exporter.Opts.Namespaces)
+		if ttlLagList != nil {
+			ttlLagList.Export(ch)
+		}
+	}
+
+	if exporter.Opts.CollectIndexBuilds {
+		log.Debug("Collecting Index Builds")
+		indexBuildList := mongod.GetIndexBuildList(client, replSet)
+		if indexBuildList != nil {
+			indexBuildList.Export(ch)
+		}
+	}
+
 	if exporter.Opts.CollectConnPoolStats {
 		log.Debug("Collecting ConnPoolStats Metrics")
 		connPoolStats := commoncollector.GetConnPoolStats(client)
@@ -323,23 +463,35 @@ func (exporter *MongodbCollector) collectMongod(client *mongo.Client, ch chan<-
 	}
 }
 
-func (exporter *MongodbCollector) collectMongodReplSet(client *mongo.Client, ch chan<- prometheus.Metric) {
-	exporter.collectMongod(client, ch)
+func (exporter *MongodbCollector) collectMongodReplSet(client *mongo.Client, serverVersion string, ch chan<- prometheus.Metric) {
+	exporter.collectMongod(client, true, ch)
 
 	log.Debug("Collecting ReplSetConf Metrics")
 	replSetConf := mongod.GetReplSetConf(client)
 	if replSetConf != nil {
 		replSetConf.Export(ch)
+		replSetConf.ExportMemberInfo(ch, exporter.Opts.ReplSetTags)
 	}
 
 	log.Debug("Collecting Replset Status")
-	replSetStatus := mongod.GetReplSetStatus(client)
+	replSetStatus := mongod.GetReplSetStatus(client, shared.ServerVersionAtLeast(serverVersion, 3, 4))
 	if replSetStatus != nil {
+		exporter.primaryChanges.Observe(replSetStatus)
 		replSetStatus.Export(ch)
+		exporter.primaryChanges.Export(ch)
+
+		exporter.rollbacks.Observe(replSetStatus, mongod.GetRollbackID(client), time.Now())
+		exporter.rollbacks.Export(ch)
+	}
+
+	if replSetConf != nil && replSetStatus != nil {
+		log.Debug("Collecting Replset Health")
+		mongod.NewReplSetHealth(replSetConf, replSetStatus).Export(ch)
+		replSetStatus.ExportEffectiveReplicationLag(ch, replSetConf)
 	}
 
 	log.Debug("Collecting Replset Oplog Status")
-	oplogStatus := mongod.GetOplogStatus(client)
+	oplogStatus := mongod.GetOplogStatus(client, exporter.oplogChurn)
 	if oplogStatus != nil {
 		oplogStatus.Export(ch)
 	}
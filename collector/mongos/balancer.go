@@ -0,0 +1,395 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongos
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/percona/mongodb_exporter/shared"
+)
+
+// balancerModes are the modes reported by the balancerStatus command.
+var balancerModes = []string{"full", "autoSplitOnly", "off"}
+
+var (
+	balancerModeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "balancer_mode"),
+		"The current mode of the Cluster balancer (1) among all modes (0)",
+		[]string{"mode"},
+		nil,
+	)
+	balancerInRoundDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "balancer_in_round"),
+		"Boolean reporting if the Cluster balancer is running a balancing round (1 = yes/0 = no)",
+		nil,
+		nil,
+	)
+	balancerRoundsTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "balancer_rounds_total"),
+		"Total # of Cluster balancer rounds since the config server primary started",
+		nil,
+		nil,
+	)
+	balancerActiveWindowDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "balancer_active_window_seconds"),
+		"The configured Cluster balancer active window start and stop, as seconds since midnight in the time zone of the config server primary",
+		[]string{"boundary"},
+		nil,
+	)
+	migrationsActiveDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "migrations_active"),
+		"The # of chunk migrations in progress by namespace, donor and recipient shard",
+		[]string{"ns", "from_shard", "to_shard"},
+		nil,
+	)
+	migrationAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "migration_age_seconds"),
+		"The time since the oldest chunk migration in progress by namespace, donor and recipient shard started",
+		[]string{"ns", "from_shard", "to_shard"},
+		nil,
+	)
+	balancerRoundsLoggedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "balancer_rounds_logged"),
+		"The # of Cluster balancer rounds by result still retained in the capped config.actionlog, a gauge that drops as old rounds are removed, see balancer_rounds_total for a counter",
+		[]string{"result"},
+		nil,
+	)
+	balancerLastRoundDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "balancer_last_round_timestamp"),
+		"The unix timestamp of the last Cluster balancer round in config.actionlog",
+		nil,
+		nil,
+	)
+	balancerLastRoundFailedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "balancer_last_round_failed"),
+		"Boolean reporting if the last Cluster balancer round in config.actionlog failed (1 = failed/0 = succeeded)",
+		nil,
+		nil,
+	)
+)
+
+// BalancerStatus is the result of the balancerStatus command (3.4+).
+type BalancerStatus struct {
+	Mode              string  `bson:"mode"`
+	InBalancerRound   bool    `bson:"inBalancerRound"`
+	NumBalancerRounds float64 `bson:"numBalancerRounds"`
+}
+
+// BalancerActiveWindow is the balancer active window of config.settings, as "HH:MM" times.
+type BalancerActiveWindow struct {
+	Start string `bson:"start"`
+	Stop  string `bson:"stop"`
+}
+
+// Migration is a chunk migration in progress from config.migrations.
+type Migration struct {
+	NS        string   `bson:"ns"`
+	FromShard string   `bson:"fromShard"`
+	ToShard   string   `bson:"toShard"`
+	Min       bson.Raw `bson:"min"`
+	// Start is the time of the moveChunk.start changelog event of the migration, zero if unknown.
+	Start time.Time `bson:"-"`
+}
+
+// BalancerRound is a balancer round of config.actionlog.
+type BalancerRound struct {
+	Time    time.Time `bson:"time"`
+	Details struct {
+		// the field name is misspelled by the server
+		ErrorOccured bool `bson:"errorOccured"`
+	} `bson:"details"`
+}
+
+// BalancerStats are the Cluster balancer state and activity.
+type BalancerStats struct {
+	Status       *BalancerStatus
+	ActiveWindow *BalancerActiveWindow
+	Migrations   []Migration
+	// RoundsSucceeded and RoundsFailed are the balancer rounds retained in the capped config.actionlog.
+	RoundsSucceeded float64
+	RoundsFailed    float64
+	LastRound       *BalancerRound
+	Now             time.Time
+}
+
+// secondsSinceMidnight parses a "HH:MM" time.
+func secondsSinceMidnight(hhmm string) (float64, bool) {
+	parts := strings.Split(hhmm, ":")
+	if len(parts) != 2 {
+		return 0, false
+	}
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, false
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, false
+	}
+	return float64(hours*3600 + minutes*60), true
+}
+
+// Export exports the balancer stats.
+func (stats *BalancerStats) Export(ch chan<- prometheus.Metric) {
+	if status := stats.Status; status != nil {
+		for _, mode := range balancerModes {
+			value := 0.0
+			if mode == status.Mode {
+				value = 1
+			}
+			ch <- prometheus.MustNewConstMetric(balancerModeDesc, prometheus.GaugeValue, value, mode)
+		}
+		inRound := 0.0
+		if status.InBalancerRound {
+			inRound = 1
+		}
+		ch <- prometheus.MustNewConstMetric(balancerInRoundDesc, prometheus.GaugeValue, inRound)
+		ch <- prometheus.MustNewConstMetric(balancerRoundsTotalDesc, prometheus.CounterValue, status.NumBalancerRounds)
+	}
+
+	if window := stats.ActiveWindow; window != nil {
+		if start, ok := secondsSinceMidnight(window.Start); ok {
+			ch <- prometheus.MustNewConstMetric(balancerActiveWindowDesc, prometheus.GaugeValue, start, "start")
+		}
+		if stop, ok := secondsSinceMidnight(window.Stop); ok {
+			ch <- prometheus.MustNewConstMetric(balancerActiveWindowDesc, prometheus.GaugeValue, stop, "stop")
+		}
+	}
+
+	type migrationKey struct{ ns, from, to string }
+	active := make(map[migrationKey]float64)
+	oldest := make(map[migrationKey]time.Time)
+	for _, migration := range stats.Migrations {
+		key := migrationKey{migration.NS, migration.FromShard, migration.ToShard}
+		active[key]++
+		if !migration.Start.IsZero() && (oldest[key].IsZero() || migration.Start.Before(oldest[key])) {
+			oldest[key] = migration.Start
+		}
+	}
+	for key, count := range active {
+		ch <- prometheus.MustNewConstMetric(migrationsActiveDesc, prometheus.GaugeValue, count, key.ns, key.from, key.to)
+		if start, ok := oldest[key]; ok {
+			ch <- prometheus.MustNewConstMetric(migrationAgeDesc, prometheus.GaugeValue, stats.Now.Sub(start).Seconds(), key.ns, key.from, key.to)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(balancerRoundsLoggedDesc, prometheus.GaugeValue, stats.RoundsSucceeded, "succeeded")
+	ch <- prometheus.MustNewConstMetric(balancerRoundsLoggedDesc, prometheus.GaugeValue, stats.RoundsFailed, "failed")
+	if round := stats.LastRound; round != nil {
+		failed := 0.0
+		if round.Details.ErrorOccured {
+			failed = 1
+		}
+		ch <- prometheus.MustNewConstMetric(balancerLastRoundDesc, prometheus.GaugeValue, float64(round.Time.Unix()))
+		ch <- prometheus.MustNewConstMetric(balancerLastRoundFailedDesc, prometheus.GaugeValue, failed)
+	}
+}
+
+// Describe describes the balancer stats for prometheus.
+func (stats *BalancerStats) Describe(ch chan<- *prometheus.Desc) {
+	ch <- balancerModeDesc
+	ch <- balancerInRoundDesc
+	ch <- balancerRoundsTotalDesc
+	ch <- balancerActiveWindowDesc
+	ch <- migrationsActiveDesc
+	ch <- migrationAgeDesc
+	ch <- balancerRoundsLoggedDesc
+	ch <- balancerLastRoundDesc
+	ch <- balancerLastRoundFailedDesc
+}
+
+// GetBalancerStatus runs the balancerStatus command.
+func GetBalancerStatus(client *mongo.Client) *BalancerStatus {
+	status := &BalancerStatus{}
+	err := client.Database("admin").RunCommand(context.TODO(), bson.D{{Key: "balancerStatus", Value: 1}}).Decode(status)
+	if err != nil {
+		log.Errorf("Failed to get balancerStatus: %s.", err)
+		return nil
+	}
+	return status
+}
+
+// GetBalancerActiveWindow gets the balancer active window, nil if none is configured.
+func GetBalancerActiveWindow(client *mongo.Client) *BalancerActiveWindow {
+	settings := struct {
+		ActiveWindow *BalancerActiveWindow `bson:"activeWindow"`
+	}{}
+	opts := options.FindOne().SetComment(shared.GetCallerLocation())
+	err := client.Database("config").Collection("settings").FindOne(context.TODO(), bson.M{"_id": "balancer"}, opts).Decode(&settings)
+	if err != nil && err != mongo.ErrNoDocuments {
+		log.Errorf("Failed to execute find query on 'config.settings': %s.", err)
+	}
+	return settings.ActiveWindow
+}
+
+// GetMigrations gets the chunk migrations in progress, with their start time from config.changelog.
+func GetMigrations(client *mongo.Client) []Migration {
+	opts := options.Find().SetComment(shared.GetCallerLocation())
+	c, err := client.Database("config").Collection("migrations").Find(context.TODO(), bson.M{}, opts)
+	if err != nil {
+		log.Errorf("Failed to execute find query on 'config.migrations': %s.", err)
+		return nil
+	}
+	defer func() {
+		if err := c.Close(context.TODO()); err != nil {
+			log.Errorf("Could not close Find() cursor, reason: %v", err)
+		}
+	}()
+
+	var migrations []Migration
+	for c.Next(context.TODO()) {
+		migration := Migration{}
+		if err := c.Decode(&migration); err != nil {
+			log.Error(err)
+			continue
+		}
+		migrations = append(migrations, migration)
+	}
+	if err := c.Err(); err != nil {
+		log.Error(err)
+	}
+
+	setMigrationStarts(client, migrations)
+	return migrations
+}
+
+// migrationStartKey identifies the migration of the chunk of a collection starting at min.
+func migrationStartKey(ns string, min bson.Raw) string {
+	return ns + "/" + string(min)
+}
+
+// setMigrationStarts sets the start time of the migrations from their newest moveChunk.start changelog event,
+// read with a single query on config.changelog.
+func setMigrationStarts(client *mongo.Client, migrations []Migration) {
+	if len(migrations) == 0 {
+		return
+	}
+	or := make([]bson.M, 0, len(migrations))
+	for _, migration := range migrations {
+		or = append(or, bson.M{"ns": migration.NS, "details.min": migration.Min})
+	}
+	filter := bson.M{"what": "moveChunk.start", "$or": or}
+	// the capped changelog has no index on time, it is read newest first in insertion order
+	opts := options.Find().
+		SetProjection(bson.M{"ns": 1, "time": 1, "details.min": 1}).
+		SetHint(bson.M{"$natural": -1}).
+		SetComment(shared.GetCallerLocation())
+	c, err := client.Database("config").Collection("changelog").Find(context.TODO(), filter, opts)
+	if err != nil {
+		log.Errorf("Failed to execute find query on 'config.changelog': %s.", err)
+		return
+	}
+	defer func() {
+		if err := c.Close(context.TODO()); err != nil {
+			log.Errorf("Could not close Find() cursor, reason: %v", err)
+		}
+	}()
+
+	starts := make(map[string]time.Time)
+	for len(starts) < len(migrations) && c.Next(context.TODO()) {
+		event := struct {
+			NS      string    `bson:"ns"`
+			Time    time.Time `bson:"time"`
+			Details struct {
+				Min bson.Raw `bson:"min"`
+			} `bson:"details"`
+		}{}
+		if err := c.Decode(&event); err != nil {
+			log.Error(err)
+			continue
+		}
+		key := migrationStartKey(event.NS, event.Details.Min)
+		if _, ok := starts[key]; !ok {
+			starts[key] = event.Time
+		}
+	}
+	if err := c.Err(); err != nil {
+		log.Error(err)
+	}
+
+	for i := range migrations {
+		migrations[i].Start = starts[migrationStartKey(migrations[i].NS, migrations[i].Min)]
+	}
+}
+
+// getBalancerRounds counts the balancer rounds of config.actionlog and returns the last one.
+func getBalancerRounds(client *mongo.Client) (succeeded, failed float64, last *BalancerRound) {
+	coll := client.Database("config").Collection("actionlog")
+	filter := bson.M{"what": "balancer.round"}
+
+	pipeline := []bson.M{
+		{"$match": filter},
+		{"$group": bson.M{"_id": bson.M{"$eq": []interface{}{"$details.errorOccured", true}}, "count": bson.M{"$sum": 1}}},
+	}
+	c, err := coll.Aggregate(context.TODO(), pipeline, options.Aggregate().SetComment(shared.GetCallerLocation()))
+	if err != nil {
+		log.Errorf("Failed to aggregate 'config.actionlog': %s.", err)
+		return 0, 0, nil
+	}
+	defer func() {
+		if err := c.Close(context.TODO()); err != nil {
+			log.Errorf("Could not close Aggregate() cursor, reason: %v", err)
+		}
+	}()
+	for c.Next(context.TODO()) {
+		result := struct {
+			ErrorOccured bool    `bson:"_id"`
+			Count        float64 `bson:"count"`
+		}{}
+		if err := c.Decode(&result); err != nil {
+			log.Error(err)
+			continue
+		}
+		if result.ErrorOccured {
+			failed += result.Count
+		} else {
+			succeeded += result.Count
+		}
+	}
+	if err := c.Err(); err != nil {
+		log.Error(err)
+	}
+
+	round := &BalancerRound{}
+	opts := options.FindOne().SetSort(bson.M{"time": -1}).SetComment(shared.GetCallerLocation())
+	if err := coll.FindOne(context.TODO(), filter, opts).Decode(round); err != nil {
+		if err != mongo.ErrNoDocuments {
+			log.Errorf("Failed to execute find query on 'config.actionlog': %s.", err)
+		}
+		return succeeded, failed, nil
+	}
+	return succeeded, failed, round
+}
+
+// GetBalancerStats gets the balancer state and activity.
+func GetBalancerStats(client *mongo.Client) *BalancerStats {
+	stats := &BalancerStats{
+		Status:       GetBalancerStatus(client),
+		ActiveWindow: GetBalancerActiveWindow(client),
+		Migrations:   GetMigrations(client),
+		Now:          time.Now(),
+	}
+	stats.RoundsSucceeded, stats.RoundsFailed, stats.LastRound = getBalancerRounds(client)
+	return stats
+}
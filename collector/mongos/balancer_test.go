@@ -0,0 +1,77 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/percona/mongodb_exporter/testutils"
+)
+
+func TestBalancerStats(t *testing.T) {
+	status := &BalancerStatus{}
+	testutils.MustDecodeBSON(t, bson.M{"mode": "full", "inBalancerRound": true, "numBalancerRounds": int64(42), "ok": 1.0}, status)
+
+	now := time.Date(2019, 7, 1, 12, 0, 0, 0, time.UTC)
+	lastRound := &BalancerRound{Time: now.Add(-10 * time.Second)}
+	lastRound.Details.ErrorOccured = true
+	stats := &BalancerStats{
+		Status:       status,
+		ActiveWindow: &BalancerActiveWindow{Start: "23:00", Stop: "6:30"},
+		Migrations: []Migration{
+			{NS: "test.coll", FromShard: "rs1", ToShard: "rs2", Start: now.Add(-90 * time.Second)},
+			{NS: "test.coll", FromShard: "rs1", ToShard: "rs2", Start: now.Add(-30 * time.Second)},
+			{NS: "test.other", FromShard: "rs2", ToShard: "rs3"},
+		},
+		RoundsSucceeded: 7,
+		RoundsFailed:    2,
+		LastRound:       lastRound,
+		Now:             now,
+	}
+
+	values := testutils.CollectMetrics(stats.Export, "mode", "boundary", "ns", "from_shard", "to_shard", "result")
+
+	assert.Equal(t, 1.0, values["mongodb_mongos_sharding_balancer_mode/full"])
+	assert.Equal(t, 0.0, values["mongodb_mongos_sharding_balancer_mode/off"])
+	assert.Equal(t, 1.0, values["mongodb_mongos_sharding_balancer_in_round"])
+	assert.Equal(t, 42.0, values["mongodb_mongos_sharding_balancer_rounds_total"])
+	assert.Equal(t, 82800.0, values["mongodb_mongos_sharding_balancer_active_window_seconds/start"])
+	assert.Equal(t, 23400.0, values["mongodb_mongos_sharding_balancer_active_window_seconds/stop"])
+	assert.Equal(t, 2.0, values["mongodb_mongos_sharding_migrations_active/test.coll/rs1/rs2"])
+	assert.Equal(t, 90.0, values["mongodb_mongos_sharding_migration_age_seconds/test.coll/rs1/rs2"])
+	assert.Equal(t, 1.0, values["mongodb_mongos_sharding_migrations_active/test.other/rs2/rs3"])
+	_, ok := values["mongodb_mongos_sharding_migration_age_seconds/test.other/rs2/rs3"]
+	assert.False(t, ok)
+	assert.Equal(t, 7.0, values["mongodb_mongos_sharding_balancer_rounds_logged/succeeded"])
+	assert.Equal(t, 2.0, values["mongodb_mongos_sharding_balancer_rounds_logged/failed"])
+	assert.Equal(t, float64(lastRound.Time.Unix()), values["mongodb_mongos_sharding_balancer_last_round_timestamp"])
+	assert.Equal(t, 1.0, values["mongodb_mongos_sharding_balancer_last_round_failed"])
+}
+
+func TestSecondsSinceMidnight(t *testing.T) {
+	for hhmm, expected := range map[string]float64{"00:00": 0, "1:05": 3900, "23:59": 86340} {
+		seconds, ok := secondsSinceMidnight(hhmm)
+		assert.True(t, ok, hhmm)
+		assert.Equal(t, expected, seconds, hhmm)
+	}
+	for _, hhmm := range []string{"", "23", "ab:cd"} {
+		_, ok := secondsSinceMidnight(hhmm)
+		assert.False(t, ok, hhmm)
+	}
+}
@@ -2,6 +2,7 @@ package mongos
 
 import (
 	"context"
+	"sort"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/log"
@@ -49,21 +50,109 @@ var (
 	}, []string{"db", "coll"})
 )
 
+var (
+	collectionShardSizeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "db_coll_shard", "size"),
+		"The total size in memory of all records of a sharded collection on each shard",
+		[]string{"db", "coll", "shard"},
+		nil,
+	)
+	collectionShardObjectCountDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "db_coll_shard", "count"),
+		"The number of documents of a sharded collection on each shard",
+		[]string{"db", "coll", "shard"},
+		nil,
+	)
+	collectionShardStorageSizeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "db_coll_shard", "storage_size"),
+		"The storage allocated to a sharded collection for document storage on each shard",
+		[]string{"db", "coll", "shard"},
+		nil,
+	)
+	collectionShardIndexesSizeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "db_coll_shard", "indexes_size"),
+		"The total size of all indexes of a sharded collection on each shard",
+		[]string{"db", "coll", "shard"},
+		nil,
+	)
+	collectionShardSkewDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "db_coll_shard", "skew_ratio"),
+		"The size on the shard holding the most data of a sharded collection divided by the mean size per shard (1 = evenly distributed)",
+		[]string{"db", "coll"},
+		nil,
+	)
+)
+
 // CollectionStatList contains stats from all collections
 type CollectionStatList struct {
 	Members []CollectionStatus
+	// Shards are the non-draining shards of the cluster, so that shards without data of a sharded collection count as 0.
+	Shards []string
 }
 
 // CollectionStatus represents stats about a collection in database (mongod and raw from mongos)
 type CollectionStatus struct {
 	Database    string
 	Name        string
-	Size        int `bson:"size,omitempty"`
-	Count       int `bson:"count,omitempty"`
-	AvgObjSize  int `bson:"avgObjSize,omitempty"`
-	StorageSize int `bson:"storageSize,omitempty"`
-	Indexes     int `bson:"indexSizes,omitempty"`
-	IndexesSize int `bson:"totalIndexSize,omitempty"`
+	Sharded     bool                              `bson:"sharded,omitempty"`
+	Size        float64                           `bson:"size,omitempty"`
+	Count       float64                           `bson:"count,omitempty"`
+	AvgObjSize  float64                           `bson:"avgObjSize,omitempty"`
+	StorageSize float64                           `bson:"storageSize,omitempty"`
+	Indexes     float64                           `bson:"nindexes,omitempty"`
+	IndexesSize float64                           `bson:"totalIndexSize,omitempty"`
+	Shards      map[string]*CollectionShardStatus `bson:"shards,omitempty"`
+}
+
+// CollectionShardStatus represents the stats of a collection on a single shard.
+type CollectionShardStatus struct {
+	Size        float64 `bson:"size,omitempty"`
+	Count       float64 `bson:"count,omitempty"`
+	StorageSize float64 `bson:"storageSize,omitempty"`
+	IndexesSize float64 `bson:"totalIndexSize,omitempty"`
+}
+
+// Skew returns the size on the shard holding the most data of the collection divided by the mean size per shard,
+// counting the given shards without data of the collection. It returns false for an empty collection.
+func (member *CollectionStatus) Skew(shards []string) (float64, bool) {
+	var max, total, count float64
+	for _, shard := range shards {
+		if _, ok := member.Shards[shard]; !ok {
+			count++
+		}
+	}
+	for _, stats := range member.Shards {
+		if stats.Size > max {
+			max = stats.Size
+		}
+		total += stats.Size
+		count++
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return max / (total / count), true
+}
+
+// exportShards exports the distribution of a sharded collection across the shards.
+func (member *CollectionStatus) exportShards(ch chan<- prometheus.Metric, shards []string) {
+	for _, shard := range shards {
+		if _, ok := member.Shards[shard]; !ok {
+			ch <- prometheus.MustNewConstMetric(collectionShardSizeDesc, prometheus.GaugeValue, 0, member.Database, member.Name, shard)
+			ch <- prometheus.MustNewConstMetric(collectionShardObjectCountDesc, prometheus.GaugeValue, 0, member.Database, member.Name, shard)
+			ch <- prometheus.MustNewConstMetric(collectionShardStorageSizeDesc, prometheus.GaugeValue, 0, member.Database, member.Name, shard)
+			ch <- prometheus.MustNewConstMetric(collectionShardIndexesSizeDesc, prometheus.GaugeValue, 0, member.Database, member.Name, shard)
+		}
+	}
+	for shard, stats := range member.Shards {
+		ch <- prometheus.MustNewConstMetric(collectionShardSizeDesc, prometheus.GaugeValue, stats.Size, member.Database, member.Name, shard)
+		ch <- prometheus.MustNewConstMetric(collectionShardObjectCountDesc, prometheus.GaugeValue, stats.Count, member.Database, member.Name, shard)
+		ch <- prometheus.MustNewConstMetric(collectionShardStorageSizeDesc, prometheus.GaugeValue, stats.StorageSize, member.Database, member.Name, shard)
+		ch <- prometheus.MustNewConstMetric(collectionShardIndexesSizeDesc, prometheus.GaugeValue, stats.IndexesSize, member.Database, member.Name, shard)
+	}
+	if skew, ok := member.Skew(shards); ok {
+		ch <- prometheus.MustNewConstMetric(collectionShardSkewDesc, prometheus.GaugeValue, skew, member.Database, member.Name)
+	}
 }
 
 // Export exports database stats to prometheus
@@ -73,12 +162,15 @@ func (collStatList *CollectionStatList) Export(ch chan<- prometheus.Metric) {
 			"db":   member.Database,
 			"coll": member.Name,
 		}
-		collectionSize.With(ls).Set(float64(member.Size))
-		collectionObjectCount.With(ls).Set(float64(member.Count))
-		collectionAvgObjSize.With(ls).Set(float64(member.AvgObjSize))
-		collectionStorageSize.With(ls).Set(float64(member.StorageSize))
-		collectionIndexes.With(ls).Set(float64(member.Indexes))
-		collectionIndexesSize.With(ls).Set(float64(member.IndexesSize))
+		collectionSize.With(ls).Set(member.Size)
+		collectionObjectCount.With(ls).Set(member.Count)
+		collectionAvgObjSize.With(ls).Set(member.AvgObjSize)
+		collectionStorageSize.With(ls).Set(member.StorageSize)
+		collectionIndexes.With(ls).Set(member.Indexes)
+		collectionIndexesSize.With(ls).Set(member.IndexesSize)
+		if member.Sharded {
+			member.exportShards(ch, collStatList.Shards)
+		}
 	}
 	collectionSize.Collect(ch)
 	collectionObjectCount.Collect(ch)
@@ -96,6 +188,11 @@ func (collStatList *CollectionStatList) Describe(ch chan<- *prometheus.Desc) {
 	collectionStorageSize.Describe(ch)
 	collectionIndexes.Describe(ch)
 	collectionIndexesSize.Describe(ch)
+	ch <- collectionShardSizeDesc
+	ch <- collectionShardObjectCountDesc
+	ch <- collectionShardStorageSizeDesc
+	ch <- collectionShardIndexesSizeDesc
+	ch <- collectionShardSkewDesc
 }
 
 var (
@@ -115,6 +212,14 @@ func GetCollectionStatList(client *mongo.Client) *CollectionStatList {
 		return nil
 	}
 	delete(logSuppressCS, "")
+	if shards := GetShards(client); shards != nil {
+		for _, shard := range *shards {
+			if !shard.Draining {
+				collectionStatList.Shards = append(collectionStatList.Shards, shard.Shard)
+			}
+		}
+		sort.Strings(collectionStatList.Shards)
+	}
 	for _, dbName := range dbNames {
 		c, err := client.Database(dbName).ListCollections(context.TODO(), bson.M{}, options.ListCollections().SetNameOnly(true))
 		if err != nil {
@@ -0,0 +1,81 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/percona/mongodb_exporter/testutils"
+)
+
+func TestCollectionStatListExportsShards(t *testing.T) {
+	status := CollectionStatus{}
+	testutils.MustDecodeBSON(t, bson.M{
+		"sharded":        true,
+		"size":           int64(400),
+		"count":          int64(40),
+		"avgObjSize":     10.5,
+		"storageSize":    int64(200),
+		"nindexes":       int32(2),
+		"totalIndexSize": int64(64),
+		"indexSizes":     bson.M{"_id_": int64(32), "a_1": int64(32)},
+		"shards": bson.M{
+			"rs1": bson.M{"size": int64(300), "count": int64(30), "storageSize": int64(150), "totalIndexSize": int64(40)},
+			"rs2": bson.M{"size": int64(100), "count": int64(10), "storageSize": int64(50), "totalIndexSize": int64(24)},
+		},
+		"ok": 1.0,
+	}, &status)
+	status.Database, status.Name = "test", "sharded"
+	assert.Equal(t, 2.0, status.Indexes)
+	assert.Equal(t, 10.5, status.AvgObjSize)
+
+	list := &CollectionStatList{
+		Members: []CollectionStatus{status, {Database: "test", Name: "unsharded", Size: 10}},
+		Shards:  []string{"rs1", "rs2", "rs3"},
+	}
+	values := testutils.CollectMetrics(list.Export, "coll", "shard")
+
+	assert.Equal(t, 300.0, values["mongodb_mongos_db_coll_shard_size/sharded/rs1"])
+	assert.Equal(t, 100.0, values["mongodb_mongos_db_coll_shard_size/sharded/rs2"])
+	assert.Equal(t, 0.0, values["mongodb_mongos_db_coll_shard_size/sharded/rs3"])
+	assert.Equal(t, 30.0, values["mongodb_mongos_db_coll_shard_count/sharded/rs1"])
+	assert.Equal(t, 50.0, values["mongodb_mongos_db_coll_shard_storage_size/sharded/rs2"])
+	assert.Equal(t, 40.0, values["mongodb_mongos_db_coll_shard_indexes_size/sharded/rs1"])
+	// 300 bytes on rs1 against a mean of 400 / 3 bytes per shard
+	assert.InDelta(t, 2.25, values["mongodb_mongos_db_coll_shard_skew_ratio/sharded"], 1e-9)
+	assert.Equal(t, 2.0, values["mongodb_mongos_db_coll_indexes/sharded"])
+
+	assert.NotContains(t, values, "mongodb_mongos_db_coll_shard_skew_ratio/unsharded")
+	assert.NotContains(t, values, "mongodb_mongos_db_coll_shard_size/unsharded/rs1")
+}
+
+func TestCollectionStatusSkew(t *testing.T) {
+	status := &CollectionStatus{Shards: map[string]*CollectionShardStatus{"rs1": {Size: 100}, "rs2": {Size: 100}}}
+	skew, ok := status.Skew([]string{"rs1", "rs2"})
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, skew)
+
+	// data left on a draining shard counts
+	status.Shards["rs3"] = &CollectionShardStatus{Size: 100}
+	skew, ok = status.Skew([]string{"rs1", "rs2"})
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, skew)
+
+	_, ok = (&CollectionStatus{}).Skew([]string{"rs1"})
+	assert.False(t, ok)
+}
@@ -23,6 +23,12 @@ var (
 		Name:      "data_size_bytes",
 		Help:      "The total size in bytes of the uncompressed data held in this database",
 	}, []string{"db", "shard"})
+	storageSize = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "db",
+		Name:      "storage_size_bytes",
+		Help:      "The total size in bytes of the storage allocated to the collections of this database",
+	}, []string{"db", "shard"})
 	collectionsTotal = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: Namespace,
 		Subsystem: "db",
@@ -59,6 +65,7 @@ type RawStatus struct {
 	Name        string `bson:"db,omitempty"`
 	IndexSize   int    `bson:"indexSize,omitempty"`
 	DataSize    int    `bson:"dataSize,omitempty"`
+	StorageSize int    `bson:"storageSize,omitempty"`
 	Collections int    `bson:"collections,omitempty"`
 	Objects     int    `bson:"objects,omitempty"`
 	Indexes     int    `bson:"indexes,omitempty"`
@@ -75,6 +82,7 @@ func (dbStatList *DatabaseStatList) Export(ch chan<- prometheus.Metric) {
 				}
 				indexSize.With(ls).Set(float64(stats.IndexSize))
 				dataSize.With(ls).Set(float64(stats.DataSize))
+				storageSize.With(ls).Set(float64(stats.StorageSize))
 				collectionsTotal.With(ls).Set(float64(stats.Collections))
 				indexesTotal.With(ls).Set(float64(stats.Indexes))
 				objectsTotal.With(ls).Set(float64(stats.Objects))
@@ -84,12 +92,14 @@ func (dbStatList *DatabaseStatList) Export(ch chan<- prometheus.Metric) {
 
 	indexSize.Collect(ch)
 	dataSize.Collect(ch)
+	storageSize.Collect(ch)
 	collectionsTotal.Collect(ch)
 	indexesTotal.Collect(ch)
 	objectsTotal.Collect(ch)
 
 	indexSize.Reset()
 	dataSize.Reset()
+	storageSize.Reset()
 	collectionsTotal.Reset()
 	indexesTotal.Reset()
 	objectsTotal.Reset()
@@ -99,6 +109,7 @@ func (dbStatList *DatabaseStatList) Export(ch chan<- prometheus.Metric) {
 func (dbStatList *DatabaseStatList) Describe(ch chan<- *prometheus.Desc) {
 	indexSize.Describe(ch)
 	dataSize.Describe(ch)
+	storageSize.Describe(ch)
 	collectionsTotal.Describe(ch)
 	indexesTotal.Describe(ch)
 	objectsTotal.Describe(ch)
@@ -29,7 +29,8 @@ import (
 type ServerStatus struct {
 	commoncollector.ServerStatus `bson:",inline"`
 
-	Metrics *MetricsStats `bson:"metrics"`
+	Metrics      *MetricsStats      `bson:"metrics"`
+	Transactions *TransactionsStats `bson:"transactions"`
 }
 
 // Export exports the server status to be consumed by prometheus.
@@ -38,6 +39,9 @@ func (status *ServerStatus) Export(ch chan<- prometheus.Metric) {
 	if status.Metrics != nil {
 		status.Metrics.Export(ch)
 	}
+	if status.Transactions != nil {
+		status.Transactions.Export(ch)
+	}
 }
 
 // Describe describes the server status for prometheus.
@@ -46,6 +50,9 @@ func (status *ServerStatus) Describe(ch chan<- *prometheus.Desc) {
 	if status.Metrics != nil {
 		status.Metrics.Describe(ch)
 	}
+	if status.Transactions != nil {
+		status.Transactions.Describe(ch)
+	}
 }
 
 // GetServerStatus returns the server status info.
@@ -16,18 +16,23 @@ package mongos
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/log"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/percona/mongodb_exporter/shared"
 )
 
 var (
 	shardingChangelogInfoDesc = prometheus.NewDesc(
 		prometheus.BuildFQName(Namespace, "sharding", "changelog_10min_total"),
-		"Total # of Cluster Balancer log events over the last 10 minutes",
+		"Total # of Cluster Balancer log events over the last 10 minutes, see changelog_events_total for a counter",
 		[]string{"event"},
 		nil,
 	)
@@ -49,18 +54,18 @@ type ShardingChangelogStats struct {
 
 func (status *ShardingChangelogStats) Export(ch chan<- prometheus.Metric) {
 	// set all expected event types to zero first, so they show in results if there was no events in the current time period
-	ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.CounterValue, 0, "moveChunk.start")
-	ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.CounterValue, 0, "moveChunk.to")
-	ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.CounterValue, 0, "moveChunk.to_failed")
-	ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.CounterValue, 0, "moveChunk.from")
-	ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.CounterValue, 0, "moveChunk.from_failed")
-	ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.CounterValue, 0, "moveChunk.commit")
-	ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.CounterValue, 0, "addShard")
-	ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.CounterValue, 0, "removeShard.start")
-	ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.CounterValue, 0, "shardCollection")
-	ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.CounterValue, 0, "shardCollection.start")
-	ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.CounterValue, 0, "split")
-	ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.CounterValue, 0, "multi-split")
+	ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.GaugeValue, 0, "moveChunk.start")
+	ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.GaugeValue, 0, "moveChunk.to")
+	ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.GaugeValue, 0, "moveChunk.to_failed")
+	ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.GaugeValue, 0, "moveChunk.from")
+	ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.GaugeValue, 0, "moveChunk.from_failed")
+	ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.GaugeValue, 0, "moveChunk.commit")
+	ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.GaugeValue, 0, "addShard")
+	ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.GaugeValue, 0, "removeShard.start")
+	ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.GaugeValue, 0, "shardCollection")
+	ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.GaugeValue, 0, "shardCollection.start")
+	ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.GaugeValue, 0, "split")
+	ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.GaugeValue, 0, "multi-split")
 
 	// set counts for events found in our query
 	for _, item := range *status.Items {
@@ -70,22 +75,22 @@ func (status *ShardingChangelogStats) Export(ch chan<- prometheus.Metric) {
 		switch event {
 		case "moveChunk.to":
 			if note == "success" || note == "" {
-				ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.CounterValue, count, event)
+				ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.GaugeValue, count, event)
 
 			} else {
-				ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.CounterValue, count, event+"_failed")
+				ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.GaugeValue, count, event+"_failed")
 
 			}
 		case "moveChunk.from":
 			if note == "success" || note == "" {
-				ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.CounterValue, count, event)
+				ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.GaugeValue, count, event)
 
 			} else {
-				ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.CounterValue, count, event+"_failed")
+				ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.GaugeValue, count, event+"_failed")
 
 			}
 		default:
-			ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.CounterValue, count, event)
+			ch <- prometheus.MustNewConstMetric(shardingChangelogInfoDesc, prometheus.GaugeValue, count, event)
 
 		}
 	}
@@ -105,6 +110,7 @@ func GetShardingChangelogStatus(client *mongo.Client) *ShardingChangelogStats {
 	c, err := coll.Aggregate(context.TODO(), []bson.M{{"$match": match}, {"$group": group}})
 	if err != nil {
 		log.Errorf("Failed to aggregate sharding changelog events: %s.", err)
+		return nil
 	}
 
 	defer c.Close(context.TODO())
@@ -126,3 +132,132 @@ func GetShardingChangelogStatus(client *mongo.Client) *ShardingChangelogStats {
 	results.Items = &qresults
 	return results
 }
+
+// ShardingChangelogEvent is a config.changelog event.
+type ShardingChangelogEvent struct {
+	ID      interface{} `bson:"_id"`
+	What    string      `bson:"what"`
+	NS      string      `bson:"ns"`
+	Time    time.Time   `bson:"time"`
+	Details struct {
+		Note string `bson:"note"`
+	} `bson:"details"`
+}
+
+// changelogReadLimit is the maximum number of config.changelog events read on each update.
+const changelogReadLimit = 10000
+
+// ShardingChangelogCounter counts the config.changelog events read since the last update.
+// The events still in the capped changelog on the first update happened before the exporter started,
+// so they only set the time to read from and are not counted.
+type ShardingChangelogCounter struct {
+	m sync.Mutex
+	// seeded is true once the first update read the changelog
+	seeded bool
+	// last is the time of the most recent event processed, and lastIDs the events processed at that time,
+	// so that events sharing the same millisecond are counted once.
+	last    time.Time
+	lastIDs map[string]bool
+	events  *prometheus.CounterVec
+}
+
+// NewShardingChangelogCounter creates a new ShardingChangelogCounter.
+func NewShardingChangelogCounter() *ShardingChangelogCounter {
+	return &ShardingChangelogCounter{
+		lastIDs: make(map[string]bool),
+		events: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "sharding",
+			Name:      "changelog_events_total",
+			Help:      "Total # of Cluster Balancer log events read from config.changelog by event, note and namespace",
+		}, []string{"event", "note", "ns"}),
+	}
+}
+
+// Update reads the new config.changelog events and counts them.
+func (counter *ShardingChangelogCounter) Update(client *mongo.Client) {
+	counter.m.Lock()
+	since := counter.last
+	counter.m.Unlock()
+
+	events, err := getShardingChangelogEvents(client, since)
+	if err != nil {
+		log.Errorf("Failed to read sharding changelog events: %s.", err)
+		return
+	}
+	counter.process(events)
+}
+
+// process counts the events not processed yet, oldest first. The events of the first call are not counted.
+func (counter *ShardingChangelogCounter) process(events []ShardingChangelogEvent) {
+	counter.m.Lock()
+	defer counter.m.Unlock()
+
+	seeded := counter.seeded
+	counter.seeded = true
+
+	for _, event := range events {
+		id := fmt.Sprint(event.ID)
+		switch {
+		case event.Time.Before(counter.last):
+			continue
+		case event.Time.Equal(counter.last):
+			if counter.lastIDs[id] {
+				continue
+			}
+		default:
+			counter.last = event.Time
+			counter.lastIDs = make(map[string]bool)
+		}
+		counter.lastIDs[id] = true
+		if seeded {
+			counter.events.WithLabelValues(event.What, event.Details.Note, event.NS).Inc()
+		}
+	}
+}
+
+// Export exports the changelog event counters.
+func (counter *ShardingChangelogCounter) Export(ch chan<- prometheus.Metric) {
+	counter.events.Collect(ch)
+}
+
+// Describe describes the changelog event counters for prometheus.
+func (counter *ShardingChangelogCounter) Describe(ch chan<- *prometheus.Desc) {
+	counter.events.Describe(ch)
+}
+
+// getShardingChangelogEvents returns the changelog events since the given time, all events if it is zero, oldest first.
+// The capped changelog has no index on time, so it is read newest first in insertion order until an older event.
+func getShardingChangelogEvents(client *mongo.Client, since time.Time) ([]ShardingChangelogEvent, error) {
+	opts := options.Find().
+		SetProjection(bson.M{"what": 1, "ns": 1, "time": 1, "details.note": 1}).
+		SetHint(bson.M{"$natural": -1}).
+		SetLimit(changelogReadLimit).
+		SetComment(shared.GetCallerLocation())
+	c, err := client.Database("config").Collection("changelog").Find(context.TODO(), bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := c.Close(context.TODO()); err != nil {
+			log.Errorf("Could not close Find() cursor, reason: %v", err)
+		}
+	}()
+
+	var events []ShardingChangelogEvent
+	for c.Next(context.TODO()) {
+		event := ShardingChangelogEvent{}
+		if err := c.Decode(&event); err != nil {
+			log.Error(err)
+			continue
+		}
+		if event.Time.Before(since) {
+			break
+		}
+		events = append(events, event)
+	}
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	return events, c.Err()
+}
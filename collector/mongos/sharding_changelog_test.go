@@ -0,0 +1,75 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/percona/mongodb_exporter/testutils"
+)
+
+func TestShardingChangelogCounter(t *testing.T) {
+	start := time.Date(2019, 7, 1, 12, 0, 0, 0, time.UTC)
+	event := func(id string, what, note, ns string, offset time.Duration) ShardingChangelogEvent {
+		e := ShardingChangelogEvent{ID: id, What: what, NS: ns, Time: start.Add(offset)}
+		e.Details.Note = note
+		return e
+	}
+	export := func(counter *ShardingChangelogCounter) map[string]float64 {
+		return testutils.CollectMetrics(counter.Export, "event", "note", "ns")
+	}
+
+	counter := NewShardingChangelogCounter()
+
+	// the events in the changelog when the exporter starts are not counted
+	counter.process([]ShardingChangelogEvent{
+		event("a", "moveChunk.from", "success", "test.coll", 0),
+		event("b", "moveChunk.from", "aborted", "test.coll", time.Second),
+		event("c", "split", "", "test.coll", 2*time.Second),
+	})
+	assert.Empty(t, export(counter))
+	assert.Equal(t, start.Add(2*time.Second), counter.last)
+
+	// the next read starts at the last time, events at that time are counted once
+	counter.process([]ShardingChangelogEvent{
+		event("c", "split", "", "test.coll", 2*time.Second),
+		event("d", "split", "", "test.coll", 2*time.Second),
+		event("e", "moveChunk.from", "success", "test.other", 3*time.Second),
+	})
+	assert.Equal(t, map[string]float64{
+		"mongodb_mongos_sharding_changelog_events_total/split//test.coll":                  1,
+		"mongodb_mongos_sharding_changelog_events_total/moveChunk.from/success/test.other": 1,
+	}, export(counter))
+
+	// nothing new
+	counter.process([]ShardingChangelogEvent{
+		event("e", "moveChunk.from", "success", "test.other", 3*time.Second),
+	})
+	assert.Equal(t, 1.0, export(counter)["mongodb_mongos_sharding_changelog_events_total/moveChunk.from/success/test.other"])
+}
+
+func TestShardingChangelogCounterEmptyChangelog(t *testing.T) {
+	counter := NewShardingChangelogCounter()
+
+	// an empty changelog at the start, the events read later are new
+	counter.process(nil)
+	counter.process([]ShardingChangelogEvent{{ID: "a", What: "split", NS: "test.coll", Time: time.Unix(1561982400, 0)}})
+	assert.Equal(t, map[string]float64{
+		"mongodb_mongos_sharding_changelog_events_total/split//test.coll": 1,
+	}, testutils.CollectMetrics(counter.Export, "event", "note", "ns"))
+}
@@ -0,0 +1,295 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongos
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/percona/mongodb_exporter/shared"
+)
+
+var (
+	collectionChunksDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "collection_chunks"),
+		"The number of chunks of a sharded collection on each shard",
+		[]string{"ns", "shard"},
+		nil,
+	)
+	collectionJumboChunksDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "collection_jumbo_chunks"),
+		"The number of chunks of a sharded collection flagged as jumbo",
+		[]string{"ns"},
+		nil,
+	)
+	collectionChunksImbalanceDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "collection_chunks_imbalance"),
+		"The difference between the number of chunks of a sharded collection on the shards with the most and the fewest chunks",
+		[]string{"ns"},
+		nil,
+	)
+	collectionBalancedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "collection_balanced"),
+		"Boolean reporting if the chunks of a sharded collection are within the migration threshold (1 = yes/0 = no)",
+		[]string{"ns"},
+		nil,
+	)
+)
+
+// ShardingCollectionChunks is the chunk distribution of a sharded collection.
+type ShardingCollectionChunks struct {
+	NS          string
+	ShardChunks map[string]float64
+	Jumbo       float64
+}
+
+// ShardingCollectionChunksList contains the chunk distribution of all sharded collections.
+type ShardingCollectionChunksList struct {
+	Items []ShardingCollectionChunks
+	// Shards are the shards chunks can be migrated to, so that shards without chunks of a collection count as 0.
+	Shards []string
+	// ChunkThresholds is true if the balancer of the cluster compares chunk counts.
+	ChunkThresholds bool
+}
+
+// shardingChunksRow is a result of the chunk distribution aggregation.
+type shardingChunksRow struct {
+	NS     string  `bson:"ns"`
+	Shard  string  `bson:"shard"`
+	Chunks float64 `bson:"chunks"`
+	Jumbo  float64 `bson:"jumbo"`
+}
+
+// MigrationThreshold returns the difference of chunks between shards above which the balancer migrates chunks
+// of a collection with the given number of chunks.
+// See https://docs.mongodb.com/v4.2/core/sharding-balancer-administration/#sharding-migration-thresholds
+func MigrationThreshold(chunks float64) float64 {
+	switch {
+	case chunks < 20:
+		return 2
+	case chunks < 80:
+		return 4
+	default:
+		return 8
+	}
+}
+
+// usesChunkThresholds returns true if the balancer of the given server version balances chunk counts.
+// Since 6.0.3 the balancer compares the data size of the collections on the shards instead.
+func usesChunkThresholds(version string) bool {
+	var parts [3]int
+	for i, part := range strings.SplitN(version, ".", 3) {
+		// strip suffixes like "-rc0"
+		if end := strings.IndexFunc(part, func(r rune) bool { return r < '0' || r > '9' }); end >= 0 {
+			part = part[:end]
+		}
+		parts[i], _ = strconv.Atoi(part)
+	}
+	major, minor, patch := parts[0], parts[1], parts[2]
+	return major < 6 || (major == 6 && minor == 0 && patch < 3)
+}
+
+// Imbalance returns the difference between the number of chunks on the shards with the most and the fewest chunks.
+// Draining or removed shards only count for the most chunks, as the balancer moves their chunks away.
+func (stats *ShardingCollectionChunks) Imbalance(shards []string) float64 {
+	var min, max float64 = -1, 0
+	for _, shard := range shards {
+		if chunks := stats.ShardChunks[shard]; min == -1 || chunks < min {
+			min = chunks
+		}
+	}
+	for _, chunks := range stats.ShardChunks {
+		if chunks > max {
+			max = chunks
+		}
+		// without known active shards all shards count for the fewest chunks
+		if len(shards) == 0 && (min == -1 || chunks < min) {
+			min = chunks
+		}
+	}
+	if min == -1 {
+		return 0
+	}
+	return max - min
+}
+
+// Total returns the number of chunks of the collection.
+func (stats *ShardingCollectionChunks) Total() float64 {
+	var total float64
+	for _, chunks := range stats.ShardChunks {
+		total += chunks
+	}
+	return total
+}
+
+// Balanced returns true if the chunks of the collection are within the migration threshold.
+func (stats *ShardingCollectionChunks) Balanced(shards []string) bool {
+	return stats.Imbalance(shards) < MigrationThreshold(stats.Total())
+}
+
+// ShardTotals returns the number of chunks of all sharded collections on each shard.
+// Shards chunks can be migrated to are reported with 0 chunks if they have none.
+func (list *ShardingCollectionChunksList) ShardTotals() map[string]float64 {
+	totals := make(map[string]float64)
+	for _, shard := range list.Shards {
+		totals[shard] = 0
+	}
+	for _, coll := range list.Items {
+		for shard, chunks := range coll.ShardChunks {
+			totals[shard] += chunks
+		}
+	}
+	return totals
+}
+
+// Total returns the number of chunks of all sharded collections.
+func (list *ShardingCollectionChunksList) Total() float64 {
+	var total float64
+	for _, coll := range list.Items {
+		total += coll.Total()
+	}
+	return total
+}
+
+// Balanced returns true if the chunks of every sharded collection are within the migration threshold.
+func (list *ShardingCollectionChunksList) Balanced() bool {
+	for i := range list.Items {
+		if !list.Items[i].Balanced(list.Shards) {
+			return false
+		}
+	}
+	return true
+}
+
+// newShardingCollectionChunksList groups the aggregation results by collection.
+func newShardingCollectionChunksList(rows []shardingChunksRow, shards []string, chunkThresholds bool) *ShardingCollectionChunksList {
+	sorted := append([]string(nil), shards...)
+	sort.Strings(sorted)
+	list := &ShardingCollectionChunksList{Shards: sorted, ChunkThresholds: chunkThresholds}
+
+	positions := make(map[string]int)
+	for _, row := range rows {
+		pos, ok := positions[row.NS]
+		if !ok {
+			pos = len(list.Items)
+			positions[row.NS] = pos
+			list.Items = append(list.Items, ShardingCollectionChunks{NS: row.NS, ShardChunks: make(map[string]float64)})
+		}
+		list.Items[pos].ShardChunks[row.Shard] += row.Chunks
+		list.Items[pos].Jumbo += row.Jumbo
+	}
+	return list
+}
+
+// Export exports the chunk distribution of the sharded collections.
+func (list *ShardingCollectionChunksList) Export(ch chan<- prometheus.Metric) {
+	for _, coll := range list.Items {
+		for _, shard := range list.Shards {
+			if _, ok := coll.ShardChunks[shard]; !ok {
+				ch <- prometheus.MustNewConstMetric(collectionChunksDesc, prometheus.GaugeValue, 0, coll.NS, shard)
+			}
+		}
+		for shard, chunks := range coll.ShardChunks {
+			ch <- prometheus.MustNewConstMetric(collectionChunksDesc, prometheus.GaugeValue, chunks, coll.NS, shard)
+		}
+		ch <- prometheus.MustNewConstMetric(collectionJumboChunksDesc, prometheus.GaugeValue, coll.Jumbo, coll.NS)
+
+		ch <- prometheus.MustNewConstMetric(collectionChunksImbalanceDesc, prometheus.GaugeValue, coll.Imbalance(list.Shards), coll.NS)
+		if list.ChunkThresholds {
+			balanced := 0.0
+			if coll.Balanced(list.Shards) {
+				balanced = 1
+			}
+			ch <- prometheus.MustNewConstMetric(collectionBalancedDesc, prometheus.GaugeValue, balanced, coll.NS)
+		}
+	}
+}
+
+// Describe describes the chunk distribution metrics for prometheus.
+func (list *ShardingCollectionChunksList) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collectionChunksDesc
+	ch <- collectionJumboChunksDesc
+	ch <- collectionChunksImbalanceDesc
+	ch <- collectionBalancedDesc
+}
+
+// GetShardingCollectionChunks gets the chunk distribution of all sharded collections with a single aggregation
+// on config.chunks. Non-draining shards without chunks of a collection are reported with 0 chunks.
+func GetShardingCollectionChunks(client *mongo.Client, shards *[]ShardingTopoShardInfo) *ShardingCollectionChunksList {
+	pipeline := []bson.M{
+		{"$group": bson.M{
+			"_id":    bson.M{"ns": "$ns", "uuid": "$uuid", "shard": "$shard"},
+			"chunks": bson.M{"$sum": 1},
+			"jumbo":  bson.M{"$sum": bson.M{"$cond": []interface{}{bson.M{"$eq": []interface{}{"$jumbo", true}}, 1, 0}}},
+		}},
+		// chunks reference their collection by uuid instead of ns since 5.0
+		{"$lookup": bson.M{"from": "collections", "localField": "_id.uuid", "foreignField": "uuid", "as": "collection"}},
+		{"$project": bson.M{
+			"_id":    0,
+			"ns":     bson.M{"$ifNull": []interface{}{"$_id.ns", bson.M{"$arrayElemAt": []interface{}{"$collection._id", 0}}}},
+			"shard":  "$_id.shard",
+			"chunks": 1,
+			"jumbo":  1,
+		}},
+	}
+	opts := options.Aggregate().SetComment(shared.GetCallerLocation())
+	c, err := client.Database("config").Collection("chunks").Aggregate(context.TODO(), pipeline, opts)
+	if err != nil {
+		log.Errorf("Failed to execute aggregation on 'config.chunks': %s.", err)
+		return nil
+	}
+	defer func() {
+		if err := c.Close(context.TODO()); err != nil {
+			log.Errorf("Could not close Aggregate() cursor, reason: %v", err)
+		}
+	}()
+
+	var rows []shardingChunksRow
+	for c.Next(context.TODO()) {
+		row := shardingChunksRow{}
+		if err := c.Decode(&row); err != nil {
+			log.Error(err)
+			continue
+		}
+		rows = append(rows, row)
+	}
+	if err := c.Err(); err != nil {
+		log.Error(err)
+	}
+
+	var activeShards []string
+	if shards != nil {
+		for _, shard := range *shards {
+			if !shard.Draining {
+				activeShards = append(activeShards, shard.Shard)
+			}
+		}
+	}
+
+	version, _ := shared.MongoSessionServerVersion(client)
+	chunkThresholds := usesChunkThresholds(version)
+	if !chunkThresholds {
+		log.Debugf("MongoDB %s balances data size instead of chunk counts, not exporting collection_balanced", version)
+	}
+	return newShardingCollectionChunksList(rows, activeShards, chunkThresholds)
+}
@@ -0,0 +1,103 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/percona/mongodb_exporter/testutils"
+)
+
+func TestMigrationThreshold(t *testing.T) {
+	assert.Equal(t, 2.0, MigrationThreshold(1))
+	assert.Equal(t, 2.0, MigrationThreshold(19))
+	assert.Equal(t, 4.0, MigrationThreshold(20))
+	assert.Equal(t, 4.0, MigrationThreshold(21))
+	assert.Equal(t, 4.0, MigrationThreshold(79))
+	assert.Equal(t, 8.0, MigrationThreshold(80))
+}
+
+func TestUsesChunkThresholds(t *testing.T) {
+	for version, expected := range map[string]bool{
+		"3.4.24":    true,
+		"4.2.0":     true,
+		"5.0.14":    true,
+		"6.0.2":     true,
+		"6.0.3":     false,
+		"6.1.0-rc0": false,
+		"7.0.1":     false,
+		"unknown":   true,
+	} {
+		assert.Equal(t, expected, usesChunkThresholds(version), version)
+	}
+}
+
+func TestShardingCollectionChunksList(t *testing.T) {
+	rows := []shardingChunksRow{
+		{NS: "test.small", Shard: "rs1", Chunks: 3},
+		{NS: "test.small", Shard: "rs2", Chunks: 2},
+		{NS: "test.skewed", Shard: "rs1", Chunks: 30, Jumbo: 2},
+		{NS: "test.skewed", Shard: "rs2", Chunks: 10},
+		{NS: "test.draining", Shard: "rs4", Chunks: 1},
+	}
+	list := newShardingCollectionChunksList(rows, []string{"rs3", "rs2", "rs1"}, true)
+	assert.Equal(t, []string{"rs1", "rs2", "rs3"}, list.Shards)
+
+	values := testutils.CollectMetrics(list.Export, "ns", "shard")
+
+	assert.Equal(t, 3.0, values["mongodb_mongos_sharding_collection_chunks/test.small/rs1"])
+	assert.Equal(t, 0.0, values["mongodb_mongos_sharding_collection_chunks/test.small/rs3"])
+	assert.Equal(t, 3.0, values["mongodb_mongos_sharding_collection_chunks_imbalance/test.small"])
+	assert.Equal(t, 0.0, values["mongodb_mongos_sharding_collection_balanced/test.small"])
+
+	assert.Equal(t, 2.0, values["mongodb_mongos_sharding_collection_jumbo_chunks/test.skewed"])
+	assert.Equal(t, 30.0, values["mongodb_mongos_sharding_collection_chunks_imbalance/test.skewed"])
+	assert.Equal(t, 0.0, values["mongodb_mongos_sharding_collection_balanced/test.skewed"])
+
+	// chunks left on a draining shard
+	assert.Equal(t, 1.0, values["mongodb_mongos_sharding_collection_chunks/test.draining/rs4"])
+	assert.Equal(t, 1.0, values["mongodb_mongos_sharding_collection_chunks_imbalance/test.draining"])
+	assert.Equal(t, 1.0, values["mongodb_mongos_sharding_collection_balanced/test.draining"])
+
+	assert.Equal(t, map[string]float64{"rs1": 33, "rs2": 12, "rs3": 0, "rs4": 1}, list.ShardTotals())
+	assert.Equal(t, 46.0, list.Total())
+	assert.False(t, list.Balanced())
+
+	// balanced when the difference is below the threshold
+	list = newShardingCollectionChunksList([]shardingChunksRow{
+		{NS: "test.even", Shard: "rs1", Chunks: 41},
+		{NS: "test.even", Shard: "rs2", Chunks: 38},
+	}, []string{"rs1", "rs2"}, false)
+	assert.Equal(t, 3.0, list.Items[0].Imbalance(list.Shards))
+	assert.Equal(t, 79.0, list.Items[0].Total())
+	assert.True(t, list.Balanced())
+	assert.NotContains(t, testutils.CollectMetrics(list.Export), "mongodb_mongos_sharding_collection_balanced")
+}
+
+func TestShardingCollectionChunksImbalanceDraining(t *testing.T) {
+	// a nearly drained shard does not count for the fewest chunks
+	coll := &ShardingCollectionChunks{NS: "test.coll", ShardChunks: map[string]float64{"rs1": 50, "rs2": 48, "rs3": 1}}
+	assert.Equal(t, 2.0, coll.Imbalance([]string{"rs1", "rs2"}))
+	assert.True(t, coll.Balanced([]string{"rs1", "rs2"}))
+
+	// chunks left on a draining shard count for the most chunks
+	coll = &ShardingCollectionChunks{NS: "test.coll", ShardChunks: map[string]float64{"rs1": 10, "rs3": 30}}
+	assert.Equal(t, 30.0, coll.Imbalance([]string{"rs1", "rs2"}))
+
+	// without known active shards all shards count
+	assert.Equal(t, 20.0, coll.Imbalance(nil))
+}
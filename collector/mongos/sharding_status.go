@@ -39,7 +39,7 @@ var (
 		Namespace: Namespace,
 		Subsystem: "sharding",
 		Name:      "chunks_is_balanced",
-		Help:      "Boolean reporting if cluster chunks are evenly balanced across shards (1 = yes/0 = no)",
+		Help:      "Boolean reporting if the chunks of every sharded collection are within the migration threshold (1 = yes/0 = no), not exported if the balancer balances data size (6.0.3+)",
 	})
 	mongosUpSecs = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: Namespace,
@@ -84,12 +84,13 @@ type MongosBalancerLock struct {
 }
 
 type ShardingStats struct {
-	IsBalanced      float64
+	IsBalanced      *float64
 	BalancerEnabled float64
 	Changelog       *ShardingChangelogStats
 	Topology        *ShardingTopoStats
 	BalancerLock    *MongosBalancerLock
 	Mongos          *[]MongosInfo
+	Balancer        *BalancerStats
 }
 
 // GetMongosInfo gets mongos info.
@@ -146,39 +147,6 @@ func IsBalancerEnabled(client *mongo.Client) float64 {
 	return 1
 }
 
-// IsClusterBalanced check is cluster balanced.
-func IsClusterBalanced(client *mongo.Client) float64 {
-	// Different thresholds based on size
-	// http://docs.mongodb.org/manual/core/sharding-internals/#sharding-migration-thresholds
-	var threshold float64 = 8
-	totalChunkCount := GetTotalChunks(client)
-	if totalChunkCount < 20 {
-		threshold = 2
-	} else if totalChunkCount < 80 && totalChunkCount > 21 {
-		threshold = 4
-	}
-
-	var minChunkCount float64 = -1
-	var maxChunkCount float64 = 0
-	shardChunkInfoAll := GetTotalChunksByShard(client)
-	for _, shard := range *shardChunkInfoAll {
-		if shard.Chunks > maxChunkCount {
-			maxChunkCount = shard.Chunks
-		}
-		if minChunkCount == -1 || shard.Chunks < minChunkCount {
-			minChunkCount = shard.Chunks
-		}
-	}
-
-	// return true if the difference between the min and max is < the thresold
-	chunkDifference := maxChunkCount - minChunkCount
-	if chunkDifference < threshold {
-		return 1
-	}
-
-	return 0
-}
-
 func (status *ShardingStats) Export(ch chan<- prometheus.Metric) {
 	if status.Changelog != nil {
 		status.Changelog.Export(ch)
@@ -186,24 +154,36 @@ func (status *ShardingStats) Export(ch chan<- prometheus.Metric) {
 	if status.Topology != nil {
 		status.Topology.Export(ch)
 	}
-	if status.Mongos != nil && status.BalancerLock != nil {
+	if status.Balancer != nil {
+		status.Balancer.Export(ch)
+	}
+	// the balancer lock only names the mongos running the balancer before 3.4
+	var mongosBalancerLockHostPort string
+	if status.BalancerLock != nil {
 		mongosBalancerLockWho := strings.Split(status.BalancerLock.Who, ":")
-		mongosBalancerLockHostPort := mongosBalancerLockWho[0] + ":" + mongosBalancerLockWho[1]
-		mongosBalancerLockTimestamp.WithLabelValues(mongosBalancerLockHostPort).Set(float64(status.BalancerLock.When.Unix()))
+		if len(mongosBalancerLockWho) > 1 {
+			mongosBalancerLockHostPort = mongosBalancerLockWho[0] + ":" + mongosBalancerLockWho[1]
+			mongosBalancerLockTimestamp.WithLabelValues(mongosBalancerLockHostPort).Set(float64(status.BalancerLock.When.Unix()))
+		}
+	}
+	if status.Mongos != nil {
 		for _, mongos := range *status.Mongos {
 			mongosUpSecs.WithLabelValues(mongos.Name).Set(mongos.Up)
 			mongosPing.WithLabelValues(mongos.Name).Set(float64(mongos.Ping.Unix()))
-			mongosBalancerLockState.WithLabelValues(mongos.Name).Set(-1)
-			if mongos.Name == mongosBalancerLockHostPort {
-				mongosBalancerLockState.WithLabelValues(mongos.Name).Set(status.BalancerLock.State)
+			if status.BalancerLock != nil {
+				mongosBalancerLockState.WithLabelValues(mongos.Name).Set(-1)
+				if mongos.Name == mongosBalancerLockHostPort {
+					mongosBalancerLockState.WithLabelValues(mongos.Name).Set(status.BalancerLock.State)
+				}
 			}
 		}
 	}
 	balancerIsEnabled.Set(status.BalancerEnabled)
-	balancerChunksBalanced.Set(status.IsBalanced)
-
 	balancerIsEnabled.Collect(ch)
-	balancerChunksBalanced.Collect(ch)
+	if status.IsBalanced != nil {
+		balancerChunksBalanced.Set(*status.IsBalanced)
+		balancerChunksBalanced.Collect(ch)
+	}
 	mongosUpSecs.Collect(ch)
 	mongosPing.Collect(ch)
 	mongosBalancerLockState.Collect(ch)
@@ -217,6 +197,9 @@ func (status *ShardingStats) Describe(ch chan<- *prometheus.Desc) {
 	if status.Topology != nil {
 		status.Topology.Describe(ch)
 	}
+	if status.Balancer != nil {
+		status.Balancer.Describe(ch)
+	}
 	balancerIsEnabled.Describe(ch)
 	balancerChunksBalanced.Describe(ch)
 	mongosUpSecs.Describe(ch)
@@ -229,12 +212,19 @@ func (status *ShardingStats) Describe(ch chan<- *prometheus.Desc) {
 func GetShardingStatus(client *mongo.Client) *ShardingStats {
 	results := &ShardingStats{}
 
-	results.IsBalanced = IsClusterBalanced(client)
 	results.BalancerEnabled = IsBalancerEnabled(client)
 	results.Changelog = GetShardingChangelogStatus(client)
 	results.Topology = GetShardingTopoStatus(client)
+	if chunks := results.Topology.CollectionChunks; chunks != nil && chunks.ChunkThresholds {
+		isBalanced := 0.0
+		if chunks.Balanced() {
+			isBalanced = 1
+		}
+		results.IsBalanced = &isBalanced
+	}
 	results.Mongos = GetMongosInfo(client)
 	results.BalancerLock = GetMongosBalancerLock(client)
+	results.Balancer = GetBalancerStats(client)
 
 	return results
 }
@@ -16,10 +16,13 @@ package mongos
 
 import (
 	"context"
+	"strconv"
+	"strings"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/log"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
@@ -65,15 +68,66 @@ var (
 	})
 )
 
+var (
+	shardingCollectionInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "collection_info"),
+		"A sharded collection with its shard key",
+		[]string{"ns", "shard_key", "unique", "hashed"},
+		nil,
+	)
+	shardingDatabaseInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "database_info"),
+		"A database with its primary shard, and whether sharding is enabled for it (empty for 6.0+ which has no partitioned flag)",
+		[]string{"db", "primary_shard", "partitioned"},
+		nil,
+	)
+	shardingShardZoneInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "shard_zone_info"),
+		"A zone a shard is assigned to",
+		[]string{"shard", "zone"},
+		nil,
+	)
+	shardingZoneRangesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "zone_ranges"),
+		"The number of shard key ranges of a collection assigned to a zone",
+		[]string{"ns", "zone"},
+		nil,
+	)
+	shardingShardZoneRangesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "shard_zone_ranges"),
+		"The number of shard key ranges assigned to the zones of a shard",
+		[]string{"shard"},
+		nil,
+	)
+)
+
 type ShardingTopoShardInfo struct {
-	Shard    string `bson:"_id"`
-	Host     string `bson:"host"`
-	Draining bool   `bson:"draining",omitifempty`
+	Shard    string   `bson:"_id"`
+	Host     string   `bson:"host"`
+	Draining bool     `bson:"draining",omitifempty`
+	Tags     []string `bson:"tags,omitempty"`
 }
 
-type ShardingTopoChunkInfo struct {
-	Shard  string  `bson:"_id"`
-	Chunks float64 `bson:"count"`
+// ShardingTopoCollectionInfo is a sharded collection from config.collections.
+type ShardingTopoCollectionInfo struct {
+	NS     string   `bson:"_id"`
+	Key    bson.Raw `bson:"key"`
+	Unique bool     `bson:"unique"`
+}
+
+// ShardingTopoDatabaseInfo is a database from config.databases.
+type ShardingTopoDatabaseInfo struct {
+	Name    string `bson:"_id"`
+	Primary string `bson:"primary"`
+	// removed in 6.0, where sharding is always enabled
+	Partitioned *bool `bson:"partitioned,omitempty"`
+}
+
+// ShardingTopoZoneRanges is the number of ranges of a collection assigned to a zone in config.tags.
+type ShardingTopoZoneRanges struct {
+	NS     string  `bson:"ns"`
+	Zone   string  `bson:"zone"`
+	Ranges float64 `bson:"ranges"`
 }
 
 type ShardingTopoStatsTotalDatabases struct {
@@ -82,11 +136,43 @@ type ShardingTopoStatsTotalDatabases struct {
 }
 
 type ShardingTopoStats struct {
-	TotalChunks      float64
 	TotalCollections float64
 	TotalDatabases   *[]ShardingTopoStatsTotalDatabases
 	Shards           *[]ShardingTopoShardInfo
-	ShardChunks      *[]ShardingTopoChunkInfo
+	CollectionChunks *ShardingCollectionChunksList
+	Collections      *[]ShardingTopoCollectionInfo
+	Databases        *[]ShardingTopoDatabaseInfo
+	ZoneRanges       *[]ShardingTopoZoneRanges
+}
+
+// KeyPattern formats the shard key like the key of the index supporting it, e.g. {a:1,b:"hashed"}.
+func (info *ShardingTopoCollectionInfo) KeyPattern() string {
+	elements, _ := info.Key.Elements()
+	fields := make([]string, 0, len(elements))
+	for _, e := range elements {
+		value := e.Value().String()
+		switch e.Value().Type {
+		case bsontype.Double:
+			value = strconv.FormatFloat(e.Value().Double(), 'f', -1, 64)
+		case bsontype.Int32:
+			value = strconv.FormatInt(int64(e.Value().Int32()), 10)
+		case bsontype.Int64:
+			value = strconv.FormatInt(e.Value().Int64(), 10)
+		}
+		fields = append(fields, e.Key()+":"+value)
+	}
+	return "{" + strings.Join(fields, ",") + "}"
+}
+
+// Hashed returns true if the collection is sharded on a hashed key.
+func (info *ShardingTopoCollectionInfo) Hashed() bool {
+	elements, _ := info.Key.Elements()
+	for _, e := range elements {
+		if value, ok := e.Value().StringValueOK(); ok && value == "hashed" {
+			return true
+		}
+	}
+	return false
 }
 
 // GetShards gets shards.
@@ -116,27 +202,46 @@ func GetShards(client *mongo.Client) *[]ShardingTopoShardInfo {
 	return &shards
 }
 
-// GetTotalChunks gets total chunks.
-func GetTotalChunks(client *mongo.Client) float64 {
-	chunkCount, err := client.Database("config").Collection("chunks").CountDocuments(context.TODO(), bson.M{})
+// GetTotalDatabases gets total databases.
+func GetTotalDatabases(client *mongo.Client) *[]ShardingTopoStatsTotalDatabases {
+	results := []ShardingTopoStatsTotalDatabases{}
+	query := []bson.M{{"$match": bson.M{"_id": bson.M{"$ne": "admin"}}}, {"$group": bson.M{"_id": "$partitioned", "total": bson.M{"$sum": 1}}}}
+	c, err := client.Database("config").Collection("databases").Aggregate(context.TODO(), query)
 	if err != nil {
-		log.Errorf("Failed to execute find query on 'config.chunks': %s.", err)
+		log.Errorf("Failed to execute find query on 'config.databases': %s.", err)
+		return nil
 	}
-	return float64(chunkCount)
+	defer c.Close(context.TODO())
+
+	for c.Next(context.TODO()) {
+		e := &ShardingTopoStatsTotalDatabases{}
+		if err := c.Decode(e); err != nil {
+			log.Error(err)
+			continue
+		}
+		results = append(results, *e)
+	}
+
+	if err := c.Err(); err != nil {
+		log.Error(err)
+	}
+
+	return &results
 }
 
-// GetTotalChunksByShard gets total chunks by shard.
-func GetTotalChunksByShard(client *mongo.Client) *[]ShardingTopoChunkInfo {
-	var results []ShardingTopoChunkInfo
-	c, err := client.Database("config").Collection("chunks").Aggregate(context.TODO(), []bson.M{{"$group": bson.M{"_id": "$shard", "count": bson.M{"$sum": 1}}}})
+// GetShardedCollections gets the sharded collections.
+func GetShardedCollections(client *mongo.Client) *[]ShardingTopoCollectionInfo {
+	var results []ShardingTopoCollectionInfo
+	opts := options.Find().SetComment(shared.GetCallerLocation())
+	c, err := client.Database("config").Collection("collections").Find(context.TODO(), bson.M{"dropped": bson.M{"$ne": true}}, opts)
 	if err != nil {
-		log.Errorf("Failed to execute find query on 'config.chunks': %s.", err)
+		log.Errorf("Failed to execute find query on 'config.collections': %s.", err)
 		return nil
 	}
 	defer c.Close(context.TODO())
 
 	for c.Next(context.TODO()) {
-		e := &ShardingTopoChunkInfo{}
+		e := &ShardingTopoCollectionInfo{}
 		if err := c.Decode(e); err != nil {
 			log.Error(err)
 			continue
@@ -151,11 +256,11 @@ func GetTotalChunksByShard(client *mongo.Client) *[]ShardingTopoChunkInfo {
 	return &results
 }
 
-// GetTotalDatabases gets total databases.
-func GetTotalDatabases(client *mongo.Client) *[]ShardingTopoStatsTotalDatabases {
-	results := []ShardingTopoStatsTotalDatabases{}
-	query := []bson.M{{"$match": bson.M{"_id": bson.M{"$ne": "admin"}}}, {"$group": bson.M{"_id": "$partitioned", "total": bson.M{"$sum": 1}}}}
-	c, err := client.Database("config").Collection("databases").Aggregate(context.TODO(), query)
+// GetDatabases gets the databases with their primary shard.
+func GetDatabases(client *mongo.Client) *[]ShardingTopoDatabaseInfo {
+	var results []ShardingTopoDatabaseInfo
+	opts := options.Find().SetComment(shared.GetCallerLocation())
+	c, err := client.Database("config").Collection("databases").Find(context.TODO(), bson.M{"_id": bson.M{"$ne": "admin"}}, opts)
 	if err != nil {
 		log.Errorf("Failed to execute find query on 'config.databases': %s.", err)
 		return nil
@@ -163,7 +268,38 @@ func GetTotalDatabases(client *mongo.Client) *[]ShardingTopoStatsTotalDatabases
 	defer c.Close(context.TODO())
 
 	for c.Next(context.TODO()) {
-		e := &ShardingTopoStatsTotalDatabases{}
+		e := &ShardingTopoDatabaseInfo{}
+		if err := c.Decode(e); err != nil {
+			log.Error(err)
+			continue
+		}
+		results = append(results, *e)
+	}
+
+	if err := c.Err(); err != nil {
+		log.Error(err)
+	}
+
+	return &results
+}
+
+// GetZoneRanges gets the number of ranges of each collection assigned to each zone.
+func GetZoneRanges(client *mongo.Client) *[]ShardingTopoZoneRanges {
+	var results []ShardingTopoZoneRanges
+	pipeline := []bson.M{
+		{"$group": bson.M{"_id": bson.M{"ns": "$ns", "zone": "$tag"}, "ranges": bson.M{"$sum": 1}}},
+		{"$project": bson.M{"_id": 0, "ns": "$_id.ns", "zone": "$_id.zone", "ranges": 1}},
+	}
+	opts := options.Aggregate().SetComment(shared.GetCallerLocation())
+	c, err := client.Database("config").Collection("tags").Aggregate(context.TODO(), pipeline, opts)
+	if err != nil {
+		log.Errorf("Failed to execute aggregation on 'config.tags': %s.", err)
+		return nil
+	}
+	defer c.Close(context.TODO())
+
+	for c.Next(context.TODO()) {
+		e := &ShardingTopoZoneRanges{}
 		if err := c.Decode(e); err != nil {
 			log.Error(err)
 			continue
@@ -180,7 +316,8 @@ func GetTotalDatabases(client *mongo.Client) *[]ShardingTopoStatsTotalDatabases
 
 // GetTotalShardedCollections gets total sharded collections.
 func GetTotalShardedCollections(client *mongo.Client) float64 {
-	collCount, err := client.Database("config").Collection("collections").CountDocuments(context.TODO(), bson.M{"dropped": false})
+	// dropped collections are removed instead of flagged since 4.4
+	collCount, err := client.Database("config").Collection("collections").CountDocuments(context.TODO(), bson.M{"dropped": bson.M{"$ne": true}})
 	if err != nil {
 		log.Errorf("Failed to execute find query on 'config.collections': %s.", err)
 	}
@@ -198,7 +335,6 @@ func (status *ShardingTopoStats) Export(ch chan<- prometheus.Metric) {
 		shardingTopoInfoDrainingShards.Set(drainingShards)
 		shardingTopoInfoTotalShards.Set(float64(len(*status.Shards)))
 	}
-	shardingTopoInfoTotalChunks.Set(status.TotalChunks)
 	shardingTopoInfoTotalCollections.Set(status.TotalCollections)
 
 	shardingTopoInfoTotalDatabases.WithLabelValues("partitioned").Set(0)
@@ -214,15 +350,20 @@ func (status *ShardingTopoStats) Export(ch chan<- prometheus.Metric) {
 		}
 	}
 
-	if status.ShardChunks != nil {
-		// set all known shards to zero first so that shards with zero chunks are still displayed properly
-		for _, shard := range *status.Shards {
-			shardingTopoInfoShardChunks.WithLabelValues(shard.Shard).Set(0)
+	if status.CollectionChunks != nil {
+		// set all known shards to zero first so that draining shards with zero chunks are still displayed properly
+		if status.Shards != nil {
+			for _, shard := range *status.Shards {
+				shardingTopoInfoShardChunks.WithLabelValues(shard.Shard).Set(0)
+			}
 		}
-		for _, shard := range *status.ShardChunks {
-			shardingTopoInfoShardChunks.WithLabelValues(shard.Shard).Set(shard.Chunks)
+		for shard, chunks := range status.CollectionChunks.ShardTotals() {
+			shardingTopoInfoShardChunks.WithLabelValues(shard).Set(chunks)
 		}
+		shardingTopoInfoTotalChunks.Set(status.CollectionChunks.Total())
+		status.CollectionChunks.Export(ch)
 	}
+	status.exportInventory(ch)
 
 	shardingTopoInfoTotalShards.Collect(ch)
 	shardingTopoInfoDrainingShards.Collect(ch)
@@ -239,6 +380,51 @@ func (status *ShardingTopoStats) Describe(ch chan<- *prometheus.Desc) {
 	shardingTopoInfoShardChunks.Describe(ch)
 	shardingTopoInfoTotalDatabases.Describe(ch)
 	shardingTopoInfoTotalCollections.Describe(ch)
+	if status.CollectionChunks != nil {
+		status.CollectionChunks.Describe(ch)
+	}
+	ch <- shardingCollectionInfoDesc
+	ch <- shardingDatabaseInfoDesc
+	ch <- shardingShardZoneInfoDesc
+	ch <- shardingZoneRangesDesc
+	ch <- shardingShardZoneRangesDesc
+}
+
+// exportInventory exports the sharded collections, the databases and the zones.
+func (status *ShardingTopoStats) exportInventory(ch chan<- prometheus.Metric) {
+	if status.Collections != nil {
+		for _, coll := range *status.Collections {
+			ch <- prometheus.MustNewConstMetric(shardingCollectionInfoDesc, prometheus.GaugeValue, 1,
+				coll.NS, coll.KeyPattern(), strconv.FormatBool(coll.Unique), strconv.FormatBool(coll.Hashed()))
+		}
+	}
+	if status.Databases != nil {
+		for _, db := range *status.Databases {
+			partitioned := ""
+			if db.Partitioned != nil {
+				partitioned = strconv.FormatBool(*db.Partitioned)
+			}
+			ch <- prometheus.MustNewConstMetric(shardingDatabaseInfoDesc, prometheus.GaugeValue, 1, db.Name, db.Primary, partitioned)
+		}
+	}
+
+	zoneRanges := make(map[string]float64)
+	if status.ZoneRanges != nil {
+		for _, item := range *status.ZoneRanges {
+			zoneRanges[item.Zone] += item.Ranges
+			ch <- prometheus.MustNewConstMetric(shardingZoneRangesDesc, prometheus.GaugeValue, item.Ranges, item.NS, item.Zone)
+		}
+	}
+	if status.Shards != nil {
+		for _, shard := range *status.Shards {
+			var ranges float64
+			for _, zone := range shard.Tags {
+				ranges += zoneRanges[zone]
+				ch <- prometheus.MustNewConstMetric(shardingShardZoneInfoDesc, prometheus.GaugeValue, 1, shard.Shard, zone)
+			}
+			ch <- prometheus.MustNewConstMetric(shardingShardZoneRangesDesc, prometheus.GaugeValue, ranges, shard.Shard)
+		}
+	}
 }
 
 // GetShardingTopoStatus gets sharding topo status.
@@ -246,10 +432,12 @@ func GetShardingTopoStatus(client *mongo.Client) *ShardingTopoStats {
 	results := &ShardingTopoStats{}
 
 	results.Shards = GetShards(client)
-	results.TotalChunks = GetTotalChunks(client)
-	results.ShardChunks = GetTotalChunksByShard(client)
+	results.CollectionChunks = GetShardingCollectionChunks(client, results.Shards)
 	results.TotalDatabases = GetTotalDatabases(client)
 	results.TotalCollections = GetTotalShardedCollections(client)
+	results.Collections = GetShardedCollections(client)
+	results.Databases = GetDatabases(client)
+	results.ZoneRanges = GetZoneRanges(client)
 
 	return results
 }
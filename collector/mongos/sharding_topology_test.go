@@ -0,0 +1,69 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/percona/mongodb_exporter/testutils"
+)
+
+func TestShardingTopoStatsExportsInventory(t *testing.T) {
+	decode := func(doc bson.D) ShardingTopoCollectionInfo {
+		info := ShardingTopoCollectionInfo{}
+		testutils.MustDecodeBSON(t, doc, &info)
+		return info
+	}
+	partitioned := true
+	status := &ShardingTopoStats{
+		Shards: &[]ShardingTopoShardInfo{
+			{Shard: "rs1", Tags: []string{"EU", "US"}},
+			{Shard: "rs2", Tags: []string{"US"}},
+			{Shard: "rs3"},
+		},
+		Collections: &[]ShardingTopoCollectionInfo{
+			decode(bson.D{{"_id", "test.ranged"}, {"key", bson.D{{"region", 1}, {"user", 1.0}}}, {"unique", true}}),
+			decode(bson.D{{"_id", "test.hashed"}, {"key", bson.D{{"user", "hashed"}}}, {"unique", false}}),
+		},
+		Databases: &[]ShardingTopoDatabaseInfo{
+			{Name: "test", Primary: "rs1", Partitioned: &partitioned},
+			{Name: "other", Primary: "rs2"},
+		},
+		ZoneRanges: &[]ShardingTopoZoneRanges{
+			{NS: "test.ranged", Zone: "EU", Ranges: 2},
+			{NS: "test.ranged", Zone: "US", Ranges: 3},
+		},
+	}
+
+	values := testutils.CollectMetrics(status.exportInventory, "ns", "shard_key", "unique", "hashed", "db", "primary_shard", "partitioned", "shard", "zone")
+
+	assert.Equal(t, map[string]float64{
+		`mongodb_mongos_sharding_collection_info/test.ranged/{region:1,user:1}/true/false`: 1,
+		`mongodb_mongos_sharding_collection_info/test.hashed/{user:"hashed"}/false/true`:   1,
+		`mongodb_mongos_sharding_database_info/test/rs1/true`:                              1,
+		`mongodb_mongos_sharding_database_info/other/rs2/`:                                 1,
+		`mongodb_mongos_sharding_zone_ranges/test.ranged/EU`:                               2,
+		`mongodb_mongos_sharding_zone_ranges/test.ranged/US`:                               3,
+		`mongodb_mongos_sharding_shard_zone_info/rs1/EU`:                                   1,
+		`mongodb_mongos_sharding_shard_zone_info/rs1/US`:                                   1,
+		`mongodb_mongos_sharding_shard_zone_info/rs2/US`:                                   1,
+		`mongodb_mongos_sharding_shard_zone_ranges/rs1`:                                    5,
+		`mongodb_mongos_sharding_shard_zone_ranges/rs2`:                                    3,
+		`mongodb_mongos_sharding_shard_zone_ranges/rs3`:                                    0,
+	}, values)
+}
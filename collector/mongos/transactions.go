@@ -0,0 +1,122 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongos
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	transactionsCurrentDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "transactions", "current"),
+		"The current number of active, inactive and open transactions",
+		[]string{"state"},
+		nil,
+	)
+	transactionsTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "transactions", "total"),
+		"The total number of transactions started, committed and aborted since the mongos process was last started",
+		[]string{"type"},
+		nil,
+	)
+	transactionsAbortCauseTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "transactions", "abort_cause_total"),
+		"The total number of aborted transactions by cause",
+		[]string{"cause"},
+		nil,
+	)
+	transactionsParticipantsTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "transactions", "participants_total"),
+		"The total number of shards contacted for transactions and the total number of participants at commit",
+		[]string{"type"},
+		nil,
+	)
+	transactionsRequestsTargetedTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "transactions", "requests_targeted_total"),
+		"totalRequestsTargeted reports the total number of network requests targeted by the mongos as part of its transactions",
+		nil,
+		nil,
+	)
+	transactionsCommitTypesTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "transactions", "commit_types_total"),
+		"The total number of initiated and successful transaction commits by commit type",
+		[]string{"type", "state"},
+		nil,
+	)
+	transactionsCommitTypesSuccessfulDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "transactions", "commit_types_successful_duration_microseconds_total"),
+		"The total duration in microseconds of the successful transaction commits by commit type",
+		[]string{"type"},
+		nil,
+	)
+)
+
+// TransactionCommitTypeStats are the counters of a single transaction commit type.
+type TransactionCommitTypeStats struct {
+	Initiated                float64 `bson:"initiated"`
+	Successful               float64 `bson:"successful"`
+	SuccessfulDurationMicros float64 `bson:"successfulDurationMicros"`
+}
+
+// TransactionsStats are the mongos transactions stats (4.2+).
+type TransactionsStats struct {
+	CurrentActive              float64                               `bson:"currentActive"`
+	CurrentInactive            float64                               `bson:"currentInactive"`
+	CurrentOpen                float64                               `bson:"currentOpen"`
+	TotalStarted               float64                               `bson:"totalStarted"`
+	TotalCommitted             float64                               `bson:"totalCommitted"`
+	TotalAborted               float64                               `bson:"totalAborted"`
+	AbortCause                 map[string]float64                    `bson:"abortCause"`
+	TotalContactedParticipants float64                               `bson:"totalContactedParticipants"`
+	TotalParticipantsAtCommit  float64                               `bson:"totalParticipantsAtCommit"`
+	TotalRequestsTargeted      float64                               `bson:"totalRequestsTargeted"`
+	CommitTypes                map[string]TransactionCommitTypeStats `bson:"commitTypes"`
+}
+
+// Export exports the transactions stats.
+func (stats *TransactionsStats) Export(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(transactionsCurrentDesc, prometheus.GaugeValue, stats.CurrentActive, "active")
+	ch <- prometheus.MustNewConstMetric(transactionsCurrentDesc, prometheus.GaugeValue, stats.CurrentInactive, "inactive")
+	ch <- prometheus.MustNewConstMetric(transactionsCurrentDesc, prometheus.GaugeValue, stats.CurrentOpen, "open")
+
+	ch <- prometheus.MustNewConstMetric(transactionsTotalDesc, prometheus.CounterValue, stats.TotalStarted, "started")
+	ch <- prometheus.MustNewConstMetric(transactionsTotalDesc, prometheus.CounterValue, stats.TotalCommitted, "committed")
+	ch <- prometheus.MustNewConstMetric(transactionsTotalDesc, prometheus.CounterValue, stats.TotalAborted, "aborted")
+
+	for cause, val := range stats.AbortCause {
+		ch <- prometheus.MustNewConstMetric(transactionsAbortCauseTotalDesc, prometheus.CounterValue, val, cause)
+	}
+
+	ch <- prometheus.MustNewConstMetric(transactionsParticipantsTotalDesc, prometheus.CounterValue, stats.TotalContactedParticipants, "contacted")
+	ch <- prometheus.MustNewConstMetric(transactionsParticipantsTotalDesc, prometheus.CounterValue, stats.TotalParticipantsAtCommit, "at_commit")
+	ch <- prometheus.MustNewConstMetric(transactionsRequestsTargetedTotalDesc, prometheus.CounterValue, stats.TotalRequestsTargeted)
+
+	for commitType, commitStats := range stats.CommitTypes {
+		ch <- prometheus.MustNewConstMetric(transactionsCommitTypesTotalDesc, prometheus.CounterValue, commitStats.Initiated, commitType, "initiated")
+		ch <- prometheus.MustNewConstMetric(transactionsCommitTypesTotalDesc, prometheus.CounterValue, commitStats.Successful, commitType, "successful")
+		ch <- prometheus.MustNewConstMetric(transactionsCommitTypesSuccessfulDurationDesc, prometheus.CounterValue, commitStats.SuccessfulDurationMicros, commitType)
+	}
+}
+
+// Describe describes the transactions stats for prometheus.
+func (stats *TransactionsStats) Describe(ch chan<- *prometheus.Desc) {
+	ch <- transactionsCurrentDesc
+	ch <- transactionsTotalDesc
+	ch <- transactionsAbortCauseTotalDesc
+	ch <- transactionsParticipantsTotalDesc
+	ch <- transactionsRequestsTargetedTotalDesc
+	ch <- transactionsCommitTypesTotalDesc
+	ch <- transactionsCommitTypesSuccessfulDurationDesc
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/percona/exporter_shared"
@@ -46,7 +47,17 @@ var (
 	collectCollectionF           = kingpin.Flag("collect.collection", "Enable collection of Collection metrics").Bool()
 	collectTopF                  = kingpin.Flag("collect.topmetrics", "Enable collection of table top metrics").Bool()
 	collectIndexUsageF           = kingpin.Flag("collect.indexusage", "Enable collection of per index usage stats").Bool()
+	indexUnusedAgeF              = kingpin.Flag("collect.indexusage.unused-age", "Minimum time without any access for an index to be reported as unused").Default("168h").Duration()
 	mongodbCollectConnPoolStatsF = kingpin.Flag("collect.connpoolstats", "Collect MongoDB connpoolstats").Bool()
+	collectLogFileF              = kingpin.Flag("collect.logfile", "Path to the mongod log file to follow and parse into metrics (disabled if empty)").Default("").String()
+	collectCommandsF             = kingpin.Flag("collect.commands", "Comma-separated list of commands to export metrics.commands counters for (all commands if empty)").Default("").String()
+	collectIndexDetailsF         = kingpin.Flag("collect.indexdetails", "Enable collection of per index WiredTiger stats").Bool()
+	collectIndexInfoF            = kingpin.Flag("collect.indexinfo", "Enable collection of index definitions").Bool()
+	collectTTLLagF               = kingpin.Flag("collect.ttllag", "Enable collection of TTL index lag").Bool()
+	collectIndexBuildsF          = kingpin.Flag("collect.indexbuilds", "Enable collection of in-progress index builds").Bool()
+	oplogChurnWindowF            = kingpin.Flag("collect.oplogchurn.window", "Window of recent oplog entries to aggregate by operation and namespace, aggregated again once per window (disabled if 0)").Default("0s").Duration()
+	collectNamespacesF           = kingpin.Flag("collect.namespaces", "Comma-separated list of databases and db.collection namespaces to run per-namespace collectors for (all namespaces if empty)").Default("").String()
+	replSetTagsF                 = kingpin.Flag("collect.replset.tags", "Comma-separated list of replica set member tag keys to promote to member_info labels").Default("").String()
 
 	uriF = kingpin.Flag("mongodb.uri", "MongoDB URI, format").
 		PlaceHolder("[mongodb://][user:pass@]host1[:port1][,host2[:port2],...][/database][?options]").
@@ -121,10 +132,20 @@ func main() {
 		CollectCollectionMetrics: *collectCollectionF,
 		CollectTopMetrics:        *collectTopF,
 		CollectIndexUsageStats:   *collectIndexUsageF,
+		IndexUnusedAge:           *indexUnusedAgeF,
 		CollectConnPoolStats:     *mongodbCollectConnPoolStatsF,
 		SocketTimeout:            *socketTimeoutF,
 		SyncTimeout:              *syncTimeoutF,
 		AuthentificationDB:       *authDB,
+		LogFile:                  *collectLogFileF,
+		CommandsAllowlist:        splitList(*collectCommandsF),
+		CollectIndexDetails:      *collectIndexDetailsF,
+		CollectIndexInfo:         *collectIndexInfoF,
+		CollectTTLLag:            *collectTTLLagF,
+		CollectIndexBuilds:       *collectIndexBuildsF,
+		OplogChurnWindow:         *oplogChurnWindowF,
+		Namespaces:               splitList(*collectNamespacesF),
+		ReplSetTags:              splitList(*replSetTagsF),
 	})
 	prometheus.MustRegister(programCollector, mongodbCollector)
 
@@ -132,6 +153,17 @@ func main() {
 	exporter_shared.RunServer("MongoDB", *listenAddressF, *metricsPathF, promHandler)
 }
 
+// splitList splits a comma-separated flag value into its non-empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // initVersionInfo sets version info
 // If binary was build for PMM with environment variable PMM_RELEASE_VERSION
 // `--version` will be displayed in PMM format. Also `PMM Version` will be connected
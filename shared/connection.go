@@ -195,30 +195,100 @@ func MongoSessionServerVersion(client *mongo.Client) (string, error) {
 	return buildInfo.Version, nil
 }
 
-// MongoSessionNodeType returns mongo node type.
-func MongoSessionNodeType(client *mongo.Client) (string, error) {
-	masterDoc := struct {
-		SetName interface{} `bson:"setName"`
-		Hosts   interface{} `bson:"hosts"`
-		Msg     string      `bson:"msg"`
-	}{}
+// ServerVersionAtLeast returns true if the server version returned by MongoSessionServerVersion
+// is at least major.minor, false if it can't be parsed.
+func ServerVersionAtLeast(version string, major, minor int) bool {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+	versionMajor, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	versionMinor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false
+	}
+	return versionMajor > major || (versionMajor == major && versionMinor >= minor)
+}
 
-	res := client.Database("admin").RunCommand(context.TODO(), bson.D{{Key: "isMaster", Value: 1}})
+// isMasterDoc is the part of the isMaster result describing the node.
+type isMasterDoc struct {
+	SetName   interface{} `bson:"setName"`
+	Hosts     interface{} `bson:"hosts"`
+	Msg       string      `bson:"msg"`
+	ConfigSvr interface{} `bson:"configsvr"`
+}
 
-	err := res.Decode(&masterDoc)
-	if err != nil {
-		log.Errorf("Got unknown node type: %s", err)
-		return "unknown", err
+func getIsMaster(client *mongo.Client) (*isMasterDoc, error) {
+	masterDoc := &isMasterDoc{}
+	res := client.Database("admin").RunCommand(context.TODO(), bson.D{{Key: "isMaster", Value: 1}})
+	if err := res.Decode(masterDoc); err != nil {
+		return nil, err
 	}
+	return masterDoc, nil
+}
 
+// nodeType returns the node type of the isMaster result.
+func (masterDoc *isMasterDoc) nodeType() string {
 	if masterDoc.SetName != nil || masterDoc.Hosts != nil {
-		return "replset", nil
+		return "replset"
 	} else if masterDoc.Msg == "isdbgrid" {
 		// isdbgrid is always the msg value when calling isMaster on a mongos
 		// see http://docs.mongodb.org/manual/core/sharded-cluster-query-router/
-		return "mongos", nil
+		return "mongos"
+	}
+	return "mongod"
+}
+
+// MongoSessionNodeType returns mongo node type.
+func MongoSessionNodeType(client *mongo.Client) (string, error) {
+	masterDoc, err := getIsMaster(client)
+	if err != nil {
+		log.Errorf("Got unknown node type: %s", err)
+		return "unknown", err
+	}
+	return masterDoc.nodeType(), nil
+}
+
+// Roles of a node in a sharded cluster returned by MongoSessionNodeInfo.
+const (
+	ClusterRoleNone      = "none"
+	ClusterRoleMongos    = "mongos"
+	ClusterRoleConfigSvr = "configsvr"
+	ClusterRoleShardSvr  = "shardsvr"
+)
+
+// MongoSessionNodeInfo returns mongo node type and the role of the node in a sharded cluster from a single isMaster,
+// and shardingState for the members which are neither a mongos nor a config server.
+// The cluster role is empty if shardingState fails.
+func MongoSessionNodeInfo(client *mongo.Client) (nodeType, clusterRole string, err error) {
+	masterDoc, err := getIsMaster(client)
+	if err != nil {
+		log.Errorf("Got unknown node type: %s", err)
+		return "unknown", "", err
+	}
+	nodeType = masterDoc.nodeType()
+	switch {
+	case nodeType == "mongos":
+		return nodeType, ClusterRoleMongos, nil
+	case masterDoc.ConfigSvr != nil:
+		return nodeType, ClusterRoleConfigSvr, nil
+	}
+
+	shardingState := struct {
+		Enabled bool `bson:"enabled"`
+	}{}
+	res := client.Database("admin").RunCommand(context.TODO(), bson.D{{Key: "shardingState", Value: 1}})
+	if err := res.Decode(&shardingState); err != nil {
+		log.Warnf("Problem detecting the sharded cluster role: %s", err)
+		return nodeType, "", nil
+	}
+	if shardingState.Enabled {
+		return nodeType, ClusterRoleShardSvr, nil
 	}
-	return "mongod", nil
+	return nodeType, ClusterRoleNone, nil
 }
 
 // TestConnection connects to MongoDB and returns BuildInfo.
@@ -30,6 +30,15 @@ func TestRedactMongoUri(t *testing.T) {
 	}
 }
 
+func TestServerVersionAtLeast(t *testing.T) {
+	assert.True(t, ServerVersionAtLeast("3.4.0", 3, 4))
+	assert.True(t, ServerVersionAtLeast("3.6.23", 3, 4))
+	assert.True(t, ServerVersionAtLeast("4.0.0-rc1", 3, 4))
+	assert.False(t, ServerVersionAtLeast("3.2.22", 3, 4))
+	assert.False(t, ServerVersionAtLeast("2.6.12", 3, 4))
+	assert.False(t, ServerVersionAtLeast("unknown", 3, 4))
+}
+
 func TestMongoSession(t *testing.T) {
 	mso := &MongoSessionOpts{
 		URI: "mongodb://localhost:27017",
@@ -0,0 +1,98 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"context"
+	"strings"
+
+	"github.com/prometheus/common/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NamespaceFilter selects the namespaces per-namespace collectors run for.
+// Every item is either a database name or a "db.collection" namespace. An empty filter selects all namespaces.
+type NamespaceFilter []string
+
+// MatchDatabase returns true if some collections of the database may be selected by the filter.
+func (filter NamespaceFilter) MatchDatabase(db string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, item := range filter {
+		if item == db || strings.HasPrefix(item, db+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns true if the collection is selected by the filter.
+func (filter NamespaceFilter) Match(db, coll string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, item := range filter {
+		if item == db || item == db+"."+coll {
+			return true
+		}
+	}
+	return false
+}
+
+// CollectionNamespace is a collection of a database.
+type CollectionNamespace struct {
+	Database   string
+	Collection string
+}
+
+// ListCollectionNamespaces returns the collections (views excluded) selected by the filter.
+// Databases which collections can't be listed are logged and skipped.
+func ListCollectionNamespaces(client *mongo.Client, filter NamespaceFilter) ([]CollectionNamespace, error) {
+	dbNames, err := client.ListDatabaseNames(context.TODO(), bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	var namespaces []CollectionNamespace
+	for _, db := range dbNames {
+		if !filter.MatchDatabase(db) {
+			continue
+		}
+		c, err := client.Database(db).ListCollections(context.TODO(), bson.M{"type": bson.M{"$ne": "view"}}, options.ListCollections().SetNameOnly(true))
+		if err != nil {
+			log.Debugf("Could not list collections of %s, reason: %v", db, err)
+			continue
+		}
+		for c.Next(context.TODO()) {
+			coll := struct {
+				Name string `bson:"name"`
+			}{}
+			if err := c.Decode(&coll); err != nil {
+				log.Error(err)
+				continue
+			}
+			if filter.Match(db, coll.Name) {
+				namespaces = append(namespaces, CollectionNamespace{Database: db, Collection: coll.Name})
+			}
+		}
+		if err := c.Close(context.TODO()); err != nil {
+			log.Errorf("Could not close ListCollections() cursor, reason: %v", err)
+		}
+	}
+	return namespaces, nil
+}
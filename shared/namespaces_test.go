@@ -0,0 +1,39 @@
+// Copyright 2017 Percona LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespaceFilter(t *testing.T) {
+	var all NamespaceFilter
+	assert.True(t, all.MatchDatabase("test"))
+	assert.True(t, all.Match("test", "coll"))
+
+	filter := NamespaceFilter{"app", "test.coll"}
+	assert.True(t, filter.MatchDatabase("app"))
+	assert.True(t, filter.MatchDatabase("test"))
+	assert.False(t, filter.MatchDatabase("admin"))
+	assert.False(t, filter.MatchDatabase("tes"))
+
+	assert.True(t, filter.Match("app", "users"))
+	assert.True(t, filter.Match("test", "coll"))
+	assert.False(t, filter.Match("test", "other"))
+	assert.False(t, filter.Match("test", "coll2"))
+	assert.False(t, filter.Match("admin", "system.users"))
+}
@@ -0,0 +1,41 @@
+package testutils
+
+import (
+	"testing"
+
+	"github.com/percona/exporter_shared/helpers"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// MustDecodeBSON encodes doc to BSON and decodes it into result, the way a command result is decoded.
+func MustDecodeBSON(t *testing.T, doc interface{}, result interface{}) {
+	t.Helper()
+	data, err := bson.Marshal(doc)
+	require.NoError(t, err)
+	require.NoError(t, bson.Unmarshal(data, result))
+}
+
+// CollectMetrics returns the values of the metrics sent by export, keyed by the metric name followed by
+// the values of the given labels the metric has, separated by "/", e.g. "mongodb_mongod_transactions_total/committed".
+func CollectMetrics(export func(ch chan<- prometheus.Metric), labels ...string) map[string]float64 {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		export(ch)
+		close(ch)
+	}()
+
+	values := make(map[string]float64)
+	for m := range ch {
+		metric := helpers.ReadMetric(m)
+		key := metric.Name
+		for _, l := range labels {
+			if v, ok := metric.Labels[l]; ok {
+				key += "/" + v
+			}
+		}
+		values[key] = metric.Value
+	}
+	return values
+}